@@ -3,7 +3,10 @@ package ndvek
 import (
 	"errors"
 	"fmt"
+	"io"
 	"math"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/viterin/vek"
@@ -16,12 +19,13 @@ const (
 	Float64 DType = iota
 	Float32
 	Bool
+	Int64
 )
 
 // NdArray represents a multi-dimensional array with shape and data.
 type NdArray struct {
 	shape []int
-	data  any // []float64, []float32, or []bool
+	data  any // []float64, []float32, []bool, or []int64
 	dtype DType
 }
 
@@ -53,8 +57,16 @@ func (a *NdArray) BoolData() []bool {
 	return nil
 }
 
+// Int64Data returns the underlying []int64 data, or nil if the dtype is not Int64.
+func (a *NdArray) Int64Data() []int64 {
+	if a.dtype == Int64 {
+		return a.data.([]int64)
+	}
+	return nil
+}
+
 // NewNdArray creates a new NdArray given a shape and initial data.
-// Data can be []float64, []float32, or []bool.
+// Data can be []float64, []float32, []bool, or []int64.
 func NewNdArray(shape []int, data any) (*NdArray, error) {
 	size := 1
 	for _, dim := range shape {
@@ -82,6 +94,11 @@ func NewNdArray(shape []int, data any) (*NdArray, error) {
 			return nil, errors.New("data length does not match shape dimensions")
 		}
 		dtype = Bool
+	case []int64:
+		if size != len(v) {
+			return nil, errors.New("data length does not match shape dimensions")
+		}
+		dtype = Int64
 	default:
 		return nil, errors.New("unsupported data type")
 	}
@@ -145,11 +162,10 @@ func (a *NdArray) ApplyHadamardOp(op func(float64) float64) error {
 	if a.dtype == Bool {
 		return errors.New("ApplyHadamardOp not supported for Bool arrays")
 	}
-	if a.dtype == Float32 {
-		f32Data := a.data.([]float32)
-		f64Data := make([]float64, len(f32Data))
-		for i, v := range f32Data {
-			f64Data[i] = op(float64(v))
+	if a.dtype == Float32 || a.dtype == Int64 {
+		f64Data, _ := a.toFloat64()
+		for i, v := range f64Data {
+			f64Data[i] = op(v)
 		}
 		a.data = f64Data
 		a.dtype = Float64
@@ -166,15 +182,10 @@ func (a *NdArray) ApplyHadamardOp(op func(float64) float64) error {
 // ApplyOp applies an arithmetic operation with broadcasting.
 // Returns a Float64 array because op returns float64.
 func ApplyOp(a, b *NdArray, op func(float64, float64) float64) (*NdArray, error) {
-	bShape, err := broadcastShapes(a.shape, b.shape)
+	aIdx, bIdx, bShape, err := BroadcastIndices(a.shape, b.shape)
 	if err != nil {
 		return nil, err
 	}
-
-	sizeOf := ProdInt(bShape)
-	resultData := make([]float64, sizeOf)
-	result := &NdArray{shape: bShape, data: resultData, dtype: Float64}
-
 	aData, err := a.toFloat64()
 	if err != nil {
 		return nil, err
@@ -184,22 +195,101 @@ func ApplyOp(a, b *NdArray, op func(float64, float64) float64) (*NdArray, error)
 		return nil, err
 	}
 
+	resultData := make([]float64, len(aIdx))
 	for i := range resultData {
-		aIndex, err := broadcastIndex(a.shape, bShape, i)
-		if err != nil {
-			return nil, err
+		resultData[i] = op(aData[aIdx[i]], bData[bIdx[i]])
+	}
+	return &NdArray{shape: bShape, data: resultData, dtype: Float64}, nil
+}
+
+// BroadcastIndices broadcasts shape1 against shape2 and returns, for every
+// element of the broadcast shape outShape in row-major order, the flat index
+// of the corresponding source element in each operand. It is the offset
+// arithmetic behind ApplyOp, exposed for custom broadcasted kernels.
+func BroadcastIndices(shape1, shape2 []int) (idx1 []int, idx2 []int, outShape []int, err error) {
+	outShape, err = broadcastShapes(shape1, shape2)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	strides1 := broadcastStrides(shape1, outShape)
+	strides2 := broadcastStrides(shape2, outShape)
+
+	size := ProdInt(outShape)
+	idx1, idx2 = make([]int, size), make([]int, size)
+	coord := make([]int, len(outShape))
+	off1, off2 := 0, 0
+	for i := range size {
+		idx1[i], idx2[i] = off1, off2
+		// Advance the row-major odometer, rewinding axes that wrap.
+		for ax := len(outShape) - 1; ax >= 0; ax-- {
+			coord[ax]++
+			off1 += strides1[ax]
+			off2 += strides2[ax]
+			if coord[ax] < outShape[ax] {
+				break
+			}
+			off1 -= coord[ax] * strides1[ax]
+			off2 -= coord[ax] * strides2[ax]
+			coord[ax] = 0
 		}
-		bIndex, err := broadcastIndex(b.shape, bShape, i)
-		if err != nil {
-			return nil, err
+	}
+	return idx1, idx2, outShape, nil
+}
+
+// broadcastStrides returns row-major strides of shape aligned to the trailing
+// axes of outShape, with zero stride along broadcast (size-1 or missing) axes.
+func broadcastStrides(shape, outShape []int) []int {
+	strides := make([]int, len(outShape))
+	stride := 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		if shape[i] != 1 {
+			strides[len(outShape)-len(shape)+i] = stride
 		}
-		resultData[i] = op(aData[aIndex], bData[bIndex])
+		stride *= shape[i]
+	}
+	return strides
+}
+
+// gather returns a new array of the given shape whose i-th element is a's
+// element at flat offset idx[i], preserving the dtype. It materializes one
+// side of a BroadcastIndices mapping.
+func (a *NdArray) gather(shape []int, idx []int) *NdArray {
+	switch a.dtype {
+	case Float32:
+		return &NdArray{shape: shape, data: gatherData(a.data.([]float32), idx), dtype: Float32}
+	case Bool:
+		return &NdArray{shape: shape, data: gatherData(a.data.([]bool), idx), dtype: Bool}
+	case Int64:
+		return &NdArray{shape: shape, data: gatherData(a.data.([]int64), idx), dtype: Int64}
+	default:
+		return &NdArray{shape: shape, data: gatherData(a.data.([]float64), idx), dtype: Float64}
+	}
+}
+
+func gatherData[T any](src []T, idx []int) []T {
+	out := make([]T, len(idx))
+	for i, j := range idx {
+		out[i] = src[j]
+	}
+	return out
+}
+
+// applyOpPromoted is ApplyOp followed by narrowing the result to Float32 when
+// both operands are Float32, so broadcasting paths don't widen single precision.
+func applyOpPromoted(a, b *NdArray, op func(float64, float64) float64) (*NdArray, error) {
+	result, err := ApplyOp(a, b, op)
+	if err != nil {
+		return nil, err
 	}
-	return result, nil
+	return fromFloat64(result.shape, result.data.([]float64), promoteDType(a, b)), nil
 }
 
-// Add performs element-wise addition with broadcasting.
+// Add performs element-wise addition with broadcasting. Two Int64 operands
+// give an Int64 result; any other mix is computed in floating point.
 func Add(a, b *NdArray) (*NdArray, error) {
+	if a.dtype == Int64 && b.dtype == Int64 {
+		return applyOpInt64(a, b, func(x, y int64) int64 { return x + y })
+	}
 	if shapesEqual(a.shape, b.shape) {
 		if a.dtype == Float32 && b.dtype == Float32 {
 			return &NdArray{shape: a.shape, data: vek32.Add(a.data.([]float32), b.data.([]float32)), dtype: Float32}, nil
@@ -220,11 +310,14 @@ func Add(a, b *NdArray) (*NdArray, error) {
 		}
 		return &NdArray{shape: b.shape, data: vek.AddNumber(b.mustFloat64(), aVal), dtype: Float64}, nil
 	}
-	return ApplyOp(a, b, func(x, y float64) float64 { return x + y })
+	return applyOpPromoted(a, b, func(x, y float64) float64 { return x + y })
 }
 
 // Subtract performs element-wise subtraction with broadcasting.
 func Subtract(a, b *NdArray) (*NdArray, error) {
+	if a.dtype == Int64 && b.dtype == Int64 {
+		return applyOpInt64(a, b, func(x, y int64) int64 { return x - y })
+	}
 	if shapesEqual(a.shape, b.shape) {
 		if a.dtype == Float32 && b.dtype == Float32 {
 			return &NdArray{shape: a.shape, data: vek32.Sub(a.data.([]float32), b.data.([]float32)), dtype: Float32}, nil
@@ -247,11 +340,14 @@ func Subtract(a, b *NdArray) (*NdArray, error) {
 		diff := vek.SubNumber(b.mustFloat64(), aVal)
 		return &NdArray{shape: b.shape, data: vek.MulNumber(diff, -1), dtype: Float64}, nil
 	}
-	return ApplyOp(a, b, func(x, y float64) float64 { return x - y })
+	return applyOpPromoted(a, b, func(x, y float64) float64 { return x - y })
 }
 
 // Multiply performs element-wise multiplication with broadcasting.
 func Multiply(a, b *NdArray) (*NdArray, error) {
+	if a.dtype == Int64 && b.dtype == Int64 {
+		return applyOpInt64(a, b, func(x, y int64) int64 { return x * y })
+	}
 	if shapesEqual(a.shape, b.shape) {
 		if a.dtype == Float32 && b.dtype == Float32 {
 			return &NdArray{shape: a.shape, data: vek32.Mul(a.data.([]float32), b.data.([]float32)), dtype: Float32}, nil
@@ -272,10 +368,11 @@ func Multiply(a, b *NdArray) (*NdArray, error) {
 		}
 		return &NdArray{shape: b.shape, data: vek.MulNumber(b.mustFloat64(), aVal), dtype: Float64}, nil
 	}
-	return ApplyOp(a, b, func(x, y float64) float64 { return x * y })
+	return applyOpPromoted(a, b, func(x, y float64) float64 { return x * y })
 }
 
-// Divide performs element-wise division with broadcasting.
+// Divide performs element-wise division with broadcasting. Int64 operands
+// are true-divided into a Float64 result.
 func Divide(a, b *NdArray) (*NdArray, error) {
 	if shapesEqual(a.shape, b.shape) {
 		if a.dtype == Float32 && b.dtype == Float32 {
@@ -299,10 +396,240 @@ func Divide(a, b *NdArray) (*NdArray, error) {
 		inv := vek.Inv(b.mustFloat64())
 		return &NdArray{shape: b.shape, data: vek.MulNumber(inv, aVal), dtype: Float64}, nil
 	}
-	return ApplyOp(a, b, func(x, y float64) float64 { return x / y })
+	return applyOpPromoted(a, b, func(x, y float64) float64 { return x / y })
+}
+
+// applyOpInt64 applies op with broadcasting to two Int64 arrays, keeping the
+// result in Int64.
+func applyOpInt64(a, b *NdArray, op func(int64, int64) int64) (*NdArray, error) {
+	aIdx, bIdx, shape, err := BroadcastIndices(a.shape, b.shape)
+	if err != nil {
+		return nil, err
+	}
+	aData, bData := a.data.([]int64), b.data.([]int64)
+	out := make([]int64, len(aIdx))
+	for i := range out {
+		out[i] = op(aData[aIdx[i]], bData[bIdx[i]])
+	}
+	return &NdArray{shape: shape, data: out, dtype: Int64}, nil
+}
+
+// DivideSafe performs element-wise division with broadcasting, substituting fill
+// wherever the divisor is zero instead of producing Inf or NaN.
+func DivideSafe(a, b *NdArray, fill float64) (*NdArray, error) {
+	return applyOpPromoted(a, b, func(x, y float64) float64 {
+		if y == 0 {
+			return fill
+		}
+		return x / y
+	})
+}
+
+// Lerp computes a + t*(b-a) element-wise, broadcasting all three inputs in a
+// single pass. The result is Float32 only when every input is Float32.
+func Lerp(a, b, t *NdArray) (*NdArray, error) {
+	aIdx, bIdx, abShape, err := BroadcastIndices(a.shape, b.shape)
+	if err != nil {
+		return nil, err
+	}
+	abIdx, tIdx, outShape, err := BroadcastIndices(abShape, t.shape)
+	if err != nil {
+		return nil, err
+	}
+	aData, err := a.toFloat64()
+	if err != nil {
+		return nil, err
+	}
+	bData, err := b.toFloat64()
+	if err != nil {
+		return nil, err
+	}
+	tData, err := t.toFloat64()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]float64, len(abIdx))
+	for i, k := range abIdx {
+		x := aData[aIdx[k]]
+		out[i] = x + tData[tIdx[i]]*(bData[bIdx[k]]-x)
+	}
+	dtype := Float64
+	if a.dtype == Float32 && b.dtype == Float32 && t.dtype == Float32 {
+		dtype = Float32
+	}
+	return fromFloat64(outShape, out, dtype), nil
+}
+
+// WeightedSum computes sum_i weights[i]*arrays[i], broadcasting every array to
+// their common shape and accumulating into a single output buffer. The result
+// is Float32 only when every input is Float32.
+func WeightedSum(arrays []*NdArray, weights []float64) (*NdArray, error) {
+	if len(arrays) != len(weights) {
+		return nil, fmt.Errorf("got %d arrays but %d weights", len(arrays), len(weights))
+	}
+	if len(arrays) == 0 {
+		return nil, errors.New("WeightedSum requires at least one array")
+	}
+	outShape := arrays[0].shape
+	for _, arr := range arrays[1:] {
+		var err error
+		if outShape, err = broadcastShapes(outShape, arr.shape); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]float64, ProdInt(outShape))
+	dtype := Float32
+	for k, arr := range arrays {
+		data, err := arr.toFloat64()
+		if err != nil {
+			return nil, err
+		}
+		idx, _, _, err := BroadcastIndices(arr.shape, outShape)
+		if err != nil {
+			return nil, err
+		}
+		w := weights[k]
+		for i, j := range idx {
+			out[i] += w * data[j]
+		}
+		if arr.dtype != Float32 {
+			dtype = Float64
+		}
+	}
+	return fromFloat64(slices.Clone(outShape), out, dtype), nil
+}
+
+// Where selects element-wise from a where the Bool array cond is true and
+// from b where it is false, broadcasting all three to a common shape. When a
+// and b share a dtype the result keeps it; mixed numeric inputs promote to
+// Float64, and Bool cannot be mixed with numeric values.
+func Where(cond, a, b *NdArray) (*NdArray, error) {
+	if cond.dtype != Bool {
+		return nil, fmt.Errorf("Where condition must be a Bool array, got %s", dtypeName(cond.dtype))
+	}
+	abShape, err := broadcastShapes(a.shape, b.shape)
+	if err != nil {
+		return nil, err
+	}
+	shape, err := broadcastShapes(cond.shape, abShape)
+	if err != nil {
+		return nil, err
+	}
+	ci, _, _, _ := BroadcastIndices(cond.shape, shape)
+	ai, _, _, _ := BroadcastIndices(a.shape, shape)
+	bi, _, _, _ := BroadcastIndices(b.shape, shape)
+	c := cond.data.([]bool)
+
+	switch {
+	case a.dtype != b.dtype && (a.dtype == Bool || b.dtype == Bool):
+		return nil, errors.New("cannot select between Bool and numeric arrays")
+	case a.dtype != b.dtype:
+		return &NdArray{shape: shape, data: whereData(c, a.mustFloat64(), b.mustFloat64(), ci, ai, bi), dtype: Float64}, nil
+	}
+	var data any
+	switch a.dtype {
+	case Float32:
+		data = whereData(c, a.data.([]float32), b.data.([]float32), ci, ai, bi)
+	case Bool:
+		data = whereData(c, a.data.([]bool), b.data.([]bool), ci, ai, bi)
+	case Int64:
+		data = whereData(c, a.data.([]int64), b.data.([]int64), ci, ai, bi)
+	default:
+		data = whereData(c, a.data.([]float64), b.data.([]float64), ci, ai, bi)
+	}
+	return &NdArray{shape: shape, data: data, dtype: a.dtype}, nil
+}
+
+// whereData picks x[xi[i]] or y[yi[i]] for each output element by cond[ci[i]].
+func whereData[T any](cond []bool, x, y []T, ci, xi, yi []int) []T {
+	out := make([]T, len(ci))
+	for i := range out {
+		if cond[ci[i]] {
+			out[i] = x[xi[i]]
+		} else {
+			out[i] = y[yi[i]]
+		}
+	}
+	return out
+}
+
+// LogAddExp computes log(exp(a) + exp(b)) element-wise with broadcasting,
+// evaluated as max + log1p(exp(-|a-b|)) so large magnitudes do not overflow.
+// Equal infinite inputs return that infinity.
+func LogAddExp(a, b *NdArray) (*NdArray, error) {
+	return applyOpPromoted(a, b, func(x, y float64) float64 {
+		if x == y {
+			// Covers -Inf + -Inf and +Inf + +Inf, where x-y is NaN.
+			return x + math.Ln2
+		}
+		m := max(x, y)
+		return m + math.Log1p(math.Exp(-math.Abs(x-y)))
+	})
+}
+
+// DivMod computes the floor quotient and remainder of a / b element-wise with
+// broadcasting, in one pass so that a == quot*b + rem holds exactly for
+// integer-valued inputs. The quotient is rounded toward negative infinity and
+// the remainder takes the sign of the divisor, as in Python's divmod, so rem
+// equals PyMod(a, b). Division by zero yields a NaN remainder.
+func DivMod(a, b *NdArray) (quot *NdArray, rem *NdArray, err error) {
+	aIdx, bIdx, outShape, err := BroadcastIndices(a.shape, b.shape)
+	if err != nil {
+		return nil, nil, err
+	}
+	aData, err := a.toFloat64()
+	if err != nil {
+		return nil, nil, err
+	}
+	bData, err := b.toFloat64()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q, r := make([]float64, len(aIdx)), make([]float64, len(aIdx))
+	for i := range q {
+		q[i], r[i] = floorDivMod(aData[aIdx[i]], bData[bIdx[i]])
+	}
+	dtype := promoteDType(a, b)
+	return fromFloat64(outShape, q, dtype), fromFloat64(slices.Clone(outShape), r, dtype), nil
+}
+
+// Mod computes the remainder of a / b element-wise with broadcasting using Go
+// and C semantics (math.Mod): the result takes the sign of the dividend, so
+// Mod(-7, 3) is -1. Use PyMod for NumPy/Python semantics.
+func Mod(a, b *NdArray) (*NdArray, error) {
+	return applyOpPromoted(a, b, math.Mod)
+}
+
+// PyMod computes the remainder of a / b element-wise with broadcasting using
+// Python semantics (NumPy's mod and Python's %): the result takes the sign of
+// the divisor, so PyMod(-7, 3) is 2 and PyMod(7, -3) is -2. It differs from
+// Mod only when the operands have opposite signs.
+func PyMod(a, b *NdArray) (*NdArray, error) {
+	return applyOpPromoted(a, b, func(x, y float64) float64 {
+		_, r := floorDivMod(x, y)
+		return r
+	})
+}
+
+// floorDivMod returns the floor quotient and the remainder with the sign of y.
+func floorDivMod(x, y float64) (float64, float64) {
+	mod := math.Mod(x, y)
+	if mod != 0 && (mod < 0) != (y < 0) {
+		mod += y
+	}
+	if y == 0 {
+		return x / y, mod
+	}
+	return math.Round((x - mod) / y), mod
 }
 
-// Pow performs element-wise exponentiation with broadcasting.
+// Pow performs element-wise exponentiation with broadcasting. The result is
+// Float32 when both inputs are Float32. Negative bases are well defined for
+// integer exponents (e.g. (-2)^3 = -8); fractional powers of negatives are NaN,
+// as with math.Pow.
 func Pow(a, b *NdArray) (*NdArray, error) {
 	if shapesEqual(a.shape, b.shape) {
 		if a.dtype == Float32 && b.dtype == Float32 {
@@ -310,7 +637,66 @@ func Pow(a, b *NdArray) (*NdArray, error) {
 		}
 		return &NdArray{shape: a.shape, data: vek.Pow(a.mustFloat64(), b.mustFloat64()), dtype: Float64}, nil
 	}
-	return ApplyOp(a, b, func(x, y float64) float64 { return math.Pow(x, y) })
+	return applyOpPromoted(a, b, math.Pow)
+}
+
+// PowScalar raises each element to the power p. Float32 input stays Float32
+// and Int64 is widened to Float64. Small integer exponents (|p| <= 4) use
+// repeated multiplication instead of math.Pow. Fractional powers of negative
+// elements are NaN.
+func (a *NdArray) PowScalar(p float64) *NdArray {
+	pow := func(x float64) float64 { return math.Pow(x, p) }
+	if n := int(p); float64(n) == p && n >= -4 && n <= 4 {
+		pow = func(x float64) float64 { return intPow(x, n) }
+	}
+	if a.dtype == Float32 {
+		src := a.data.([]float32)
+		out := make([]float32, len(src))
+		for i, v := range src {
+			out[i] = float32(pow(float64(v)))
+		}
+		return &NdArray{shape: a.shape, data: out, dtype: Float32}
+	}
+	src := a.mustFloat64()
+	out := make([]float64, len(src))
+	for i, v := range src {
+		out[i] = pow(v)
+	}
+	return &NdArray{shape: a.shape, data: out, dtype: Float64}
+}
+
+// RPowScalar computes base^x for each element x of a, the complement of
+// PowScalar. A base of math.E is computed via Exp.
+func RPowScalar(base float64, a *NdArray) *NdArray {
+	if base == math.E {
+		return a.Exp()
+	}
+	if a.dtype == Float32 {
+		src := a.data.([]float32)
+		out := make([]float32, len(src))
+		for i, v := range src {
+			out[i] = float32(math.Pow(base, float64(v)))
+		}
+		return &NdArray{shape: a.shape, data: out, dtype: Float32}
+	}
+	src := a.mustFloat64()
+	out := make([]float64, len(src))
+	for i, v := range src {
+		out[i] = math.Pow(base, v)
+	}
+	return &NdArray{shape: a.shape, data: out, dtype: Float64}
+}
+
+// intPow computes x^n by repeated multiplication, using the reciprocal for negative n.
+func intPow(x float64, n int) float64 {
+	if n < 0 {
+		x, n = 1/x, -n
+	}
+	out := 1.0
+	for range n {
+		out *= x
+	}
+	return out
 }
 
 // Minimum performs element-wise minimum.
@@ -335,9 +721,30 @@ func Maximum(a, b *NdArray) (*NdArray, error) {
 	return &NdArray{shape: a.shape, data: vek.Maximum(a.mustFloat64(), b.mustFloat64()), dtype: Float64}, nil
 }
 
-// Shape returns the shape of the ndarray.
+// Shape returns a copy of the shape of the ndarray.
 func (a *NdArray) Shape() []int {
-	return a.shape
+	shape := make([]int, len(a.shape))
+	copy(shape, a.shape)
+	return shape
+}
+
+// Meta returns a copy of the shape together with the dtype and element count.
+func (a *NdArray) Meta() (shape []int, dtype DType, size int) {
+	return a.Shape(), a.dtype, ProdInt(a.shape)
+}
+
+// AssertShape checks the array's shape against expected, where -1 matches any
+// size along that axis, and returns a descriptive error on mismatch.
+func (a *NdArray) AssertShape(expected ...int) error {
+	if len(expected) != len(a.shape) {
+		return fmt.Errorf("shape mismatch: expected rank %d %v, got rank %d %v", len(expected), expected, len(a.shape), a.shape)
+	}
+	for i, dim := range expected {
+		if dim != -1 && dim != a.shape[i] {
+			return fmt.Errorf("shape mismatch at axis %d: expected %v, got %v", i, expected, a.shape)
+		}
+	}
+	return nil
 }
 
 func ProdInt(x []int) int {
@@ -348,12 +755,36 @@ func ProdInt(x []int) int {
 	return out
 }
 
+// Zeros creates a new float64 NdArray filled with zeros.
 func Zeros(shape []int) *NdArray {
+	return ZerosDType(shape, Float64)
+}
+
+// ZerosDType creates a new NdArray of the given dtype filled with zeros (false
+// for Bool). It panics on an unknown dtype.
+func ZerosDType(shape []int, dt DType) *NdArray {
 	size := ProdInt(shape)
-	data := make([]float64, size)
+	var data any
+	switch dt {
+	case Float64:
+		data = make([]float64, size)
+	case Float32:
+		data = make([]float32, size)
+	case Bool:
+		data = make([]bool, size)
+	case Int64:
+		data = make([]int64, size)
+	default:
+		panic(fmt.Sprintf("ZerosDType: unknown dtype %d", dt))
+	}
 	shapeCopy := make([]int, len(shape))
 	copy(shapeCopy, shape)
-	return &NdArray{shape: shapeCopy, data: data, dtype: Float64}
+	return &NdArray{shape: shapeCopy, data: data, dtype: dt}
+}
+
+// ZerosLike creates a zero-filled NdArray with the same shape and dtype as a.
+func ZerosLike(a *NdArray) *NdArray {
+	return ZerosDType(a.shape, a.dtype)
 }
 
 // Ones creates a new float64 NdArray filled with ones.
@@ -365,6 +796,43 @@ func Ones(shape []int) *NdArray {
 	return &NdArray{shape: shapeCopy, data: data, dtype: Float64}
 }
 
+// Full creates a new float64 NdArray with every element set to value.
+func Full(shape []int, value float64) *NdArray {
+	size := ProdInt(shape)
+	data := vek.Repeat(value, size)
+	shapeCopy := make([]int, len(shape))
+	copy(shapeCopy, shape)
+	return &NdArray{shape: shapeCopy, data: data, dtype: Float64}
+}
+
+// FalseArray creates a new Bool NdArray filled with false.
+func FalseArray(shape []int) *NdArray {
+	shapeCopy := make([]int, len(shape))
+	copy(shapeCopy, shape)
+	return &NdArray{shape: shapeCopy, data: make([]bool, ProdInt(shape)), dtype: Bool}
+}
+
+// TrueArray creates a new Bool NdArray filled with true.
+func TrueArray(shape []int) *NdArray {
+	a := FalseArray(shape)
+	d := a.data.([]bool)
+	for i := range d {
+		d[i] = true
+	}
+	return a
+}
+
+// NewScalar creates a Float64 array of shape [1] holding v.
+func NewScalar(v float64) *NdArray {
+	return &NdArray{shape: []int{1}, data: []float64{v}, dtype: Float64}
+}
+
+// NewScalar32 creates a Float32 array of shape [1] holding v. Combining it with
+// Float32 arrays keeps results in single precision.
+func NewScalar32(v float32) *NdArray {
+	return &NdArray{shape: []int{1}, data: []float32{v}, dtype: Float32}
+}
+
 func (a *NdArray) AddScalar(b float64) *NdArray {
 	if a.dtype == Float32 {
 		return &NdArray{shape: a.shape, data: vek32.AddNumber(a.data.([]float32), float32(b)), dtype: Float32}
@@ -393,28 +861,152 @@ func (a *NdArray) DivScalar(b float64) *NdArray {
 	return &NdArray{shape: a.shape, data: vek.DivNumber(a.mustFloat64(), b), dtype: Float64}
 }
 
-func (x *NdArray) InsertAxis(pos int) (*NdArray, error) {
-	rank := len(x.shape)
+// InsertAxis returns a view of a with a new length-1 axis at position pos
+// (negative positions count from the end of the result). Like Reshape, the
+// view shares a's data buffer, so element writes are visible through both;
+// use Copy first for an independent array. The shape slice is always fresh.
+func (a *NdArray) InsertAxis(pos int) (*NdArray, error) {
+	rank := len(a.shape)
+	if pos < -(rank+1) || pos > rank {
+		return nil, fmt.Errorf("axis position %d out of range for array of rank %d", pos, rank)
+	}
 	if pos < 0 {
 		pos += rank + 1
 	}
 
-	result := make([]int, rank+1)
-	copy(result[:pos], (x.shape)[:pos])
-	result[pos] = 1
-	copy(result[pos+1:], x.shape[pos:])
+	shape := make([]int, rank+1)
+	copy(shape[:pos], a.shape[:pos])
+	shape[pos] = 1
+	copy(shape[pos+1:], a.shape[pos:])
+
+	return &NdArray{shape: shape, data: a.data, dtype: a.dtype}, nil
+}
+
+// Flatten returns a new 1-D array holding a copy of a's elements in
+// row-major order. Unlike Ravel, writes to the result never affect a.
+func (a *NdArray) Flatten() *NdArray {
+	out := a.Copy()
+	out.shape = []int{ProdInt(a.shape)}
+	return out
+}
+
+// Ravel returns a 1-D view of a that shares its data buffer, so element
+// writes through either array are visible in both. NdArray data is always
+// contiguous, so no copy is ever needed; use Flatten for an independent copy.
+func (a *NdArray) Ravel() *NdArray {
+	return &NdArray{shape: []int{ProdInt(a.shape)}, data: a.data, dtype: a.dtype}
+}
+
+// Squeeze returns a view of a with size-1 axes removed. With no arguments
+// every size-1 axis is dropped; otherwise only the named axes are, and each
+// must have size 1. Negative axes count from the end. The view shares a's
+// data buffer.
+func (a *NdArray) Squeeze(axes ...int) (*NdArray, error) {
+	drop := make([]bool, len(a.shape))
+	if len(axes) == 0 {
+		for i, d := range a.shape {
+			drop[i] = d == 1
+		}
+	} else {
+		norm, err := normalizeAxes(axes, len(a.shape))
+		if err != nil {
+			return nil, err
+		}
+		for _, ax := range norm {
+			if a.shape[ax] != 1 {
+				return nil, fmt.Errorf("cannot squeeze axis %d of size %d", ax, a.shape[ax])
+			}
+			drop[ax] = true
+		}
+	}
+
+	shape := make([]int, 0, len(a.shape))
+	for i, d := range a.shape {
+		if !drop[i] {
+			shape = append(shape, d)
+		}
+	}
+	return &NdArray{shape: shape, data: a.data, dtype: a.dtype}, nil
+}
+
+// Reshape returns a new NdArray with the given shape that shares a's data
+// buffer; a itself is left unchanged. A single -1 entry is inferred from the
+// remaining dimensions.
+func (a *NdArray) Reshape(shape []int) (*NdArray, error) {
+	size := ProdInt(a.shape)
+	newShape := make([]int, len(shape))
+	copy(newShape, shape)
+
+	infer, known := -1, 1
+	for i, d := range newShape {
+		switch {
+		case d == -1 && infer >= 0:
+			return nil, fmt.Errorf("can only infer one dimension in shape %v", shape)
+		case d == -1:
+			infer = i
+		case d < 0:
+			return nil, fmt.Errorf("invalid dimension %d in shape %v", d, shape)
+		default:
+			known *= d
+		}
+	}
+	if infer >= 0 {
+		if known == 0 || size%known != 0 {
+			return nil, fmt.Errorf("cannot reshape array of size %d into shape %v", size, shape)
+		}
+		newShape[infer] = size / known
+	}
+
+	if ProdInt(newShape) != size {
+		return nil, fmt.Errorf("cannot reshape array of size %d into shape %v (size %d)", size, shape, ProdInt(newShape))
+	}
+	return &NdArray{shape: newShape, data: a.data, dtype: a.dtype}, nil
+}
 
-	return NewNdArray(result, x.data)
+// fromFloat64 wraps float64 data as an NdArray of the given numeric dtype,
+// narrowing to float32 when dtype is Float32.
+func fromFloat64(shape []int, data []float64, dtype DType) *NdArray {
+	if dtype == Float32 {
+		return &NdArray{shape: shape, data: vek32.FromFloat64(data), dtype: Float32}
+	}
+	return &NdArray{shape: shape, data: data, dtype: Float64}
 }
 
-func (x *NdArray) Reshape(shape []int) (*NdArray, error) {
-	if ProdInt(shape) != ProdInt(x.shape) {
-		return nil, fmt.Errorf("cannot reshape array of size %d into shape %v (size %d)", ProdInt(x.shape), shape, ProdInt(shape))
+// promoteDType returns the numeric dtype for a binary op result: Float32 only
+// when both operands are Float32, Float64 otherwise.
+func promoteDType(a, b *NdArray) DType {
+	if a.dtype == Float32 && b.dtype == Float32 {
+		return Float32
 	}
+	return Float64
+}
+
+// Resize returns a new array of the given shape, copying as many elements as
+// fit in row-major order and zero-filling any remainder. Unlike Reshape, the
+// total size may change; unlike NumPy's resize, data is not repeated cyclically.
+func (a *NdArray) Resize(shape []int) *NdArray {
 	shapeCopy := make([]int, len(shape))
 	copy(shapeCopy, shape)
-	x.shape = shapeCopy
-	return x, nil
+	size := ProdInt(shape)
+
+	switch a.dtype {
+	case Float32:
+		dst := make([]float32, size)
+		copy(dst, a.data.([]float32))
+		return &NdArray{shape: shapeCopy, data: dst, dtype: Float32}
+	case Bool:
+		dst := make([]bool, size)
+		copy(dst, a.data.([]bool))
+		return &NdArray{shape: shapeCopy, data: dst, dtype: Bool}
+	case Int64:
+		dst := make([]int64, size)
+		copy(dst, a.data.([]int64))
+		return &NdArray{shape: shapeCopy, data: dst, dtype: Int64}
+	default:
+		dst := make([]float64, size)
+		copy(dst, a.data.([]float64))
+		return &NdArray{shape: shapeCopy, data: dst, dtype: Float64}
+	}
 }
 
 // toFloat64 converts numeric data to []float64, returning an error for Bool arrays.
@@ -424,6 +1016,8 @@ func (a *NdArray) toFloat64() ([]float64, error) {
 		return a.data.([]float64), nil
 	case Float32:
 		return vek.FromFloat32(a.data.([]float32)), nil
+	case Int64:
+		return vek.FromInt64(a.data.([]int64)), nil
 	default:
 		return nil, errors.New("cannot convert Bool array to float64")
 	}
@@ -445,16 +1039,48 @@ func (a *NdArray) toFloat32() ([]float32, error) {
 		return a.data.([]float32), nil
 	case Float64:
 		return vek.ToFloat32(a.data.([]float64)), nil
+	case Int64:
+		return vek32.FromInt64(a.data.([]int64)), nil
 	default:
 		return nil, errors.New("cannot convert Bool array to float32")
 	}
 }
 
+// Get returns the element at the given multi-index as a float64.
 func (a *NdArray) Get(index []int) (float64, error) {
+	offset, err := a.flatOffset(index)
+	if err != nil {
+		return 0, err
+	}
+	return a.flatValue(offset)
+}
+
+// Set writes value at the given multi-index, validating it like Get. The
+// value is narrowed to float32 for Float32 arrays and truncated toward zero
+// for Int64 arrays.
+func (a *NdArray) Set(index []int, value float64) error {
+	offset, err := a.flatOffset(index)
+	if err != nil {
+		return err
+	}
+	switch a.dtype {
+	case Float64:
+		a.data.([]float64)[offset] = value
+	case Float32:
+		a.data.([]float32)[offset] = float32(value)
+	case Int64:
+		a.data.([]int64)[offset] = int64(value)
+	default:
+		return errors.New("Set not supported for Bool arrays; use BoolData()")
+	}
+	return nil
+}
+
+// flatOffset converts a multi-index into a row-major flat offset.
+func (a *NdArray) flatOffset(index []int) (int, error) {
 	if len(index) != len(a.shape) {
 		return 0, errors.New("index length does not match array dimensions")
 	}
-
 	offset := 0
 	for i, coord := range index {
 		if coord < 0 || coord >= a.shape[i] {
@@ -462,42 +1088,247 @@ func (a *NdArray) Get(index []int) (float64, error) {
 		}
 		offset = offset*a.shape[i] + coord
 	}
+	return offset, nil
+}
 
-	switch a.dtype {
-	case Float64:
-		return a.data.([]float64)[offset], nil
+// Slice returns a copy of the sub-array selected by one [start, stop) range
+// per leading dimension; dimensions without a range are taken whole. For a
+// [4, 4] matrix, Slice([2]int{1, 3}, [2]int{0, 2}) is the [2, 2] block of
+// rows 1-2 and columns 0-1. Ranges must satisfy 0 <= start <= stop <= dim.
+func (a *NdArray) Slice(ranges ...[2]int) (*NdArray, error) {
+	if len(ranges) > len(a.shape) {
+		return nil, fmt.Errorf("got %d ranges for an array of rank %d", len(ranges), len(a.shape))
+	}
+	lo := make([]int, len(a.shape))
+	shape := slices.Clone(a.shape)
+	for i, r := range ranges {
+		if r[0] < 0 || r[0] > r[1] || r[1] > a.shape[i] {
+			return nil, fmt.Errorf("range %v out of bounds for axis %d of size %d", r, i, a.shape[i])
+		}
+		lo[i], shape[i] = r[0], r[1]-r[0]
+	}
+
+	switch a.dtype {
+	case Float32:
+		return &NdArray{shape: shape, data: sliceData(a.data.([]float32), a.shape, lo, shape), dtype: Float32}, nil
+	case Bool:
+		return &NdArray{shape: shape, data: sliceData(a.data.([]bool), a.shape, lo, shape), dtype: Bool}, nil
+	case Int64:
+		return &NdArray{shape: shape, data: sliceData(a.data.([]int64), a.shape, lo, shape), dtype: Int64}, nil
+	default:
+		return &NdArray{shape: shape, data: sliceData(a.data.([]float64), a.shape, lo, shape), dtype: Float64}, nil
+	}
+}
+
+// sliceData copies the block of src (of shape srcShape) starting at lo with
+// extent shape into a new contiguous buffer, one innermost run at a time.
+func sliceData[T any](src []T, srcShape, lo, shape []int) []T {
+	size := ProdInt(shape)
+	out := make([]T, 0, size)
+	if size == 0 {
+		return out
+	}
+	rank := len(shape)
+	if rank == 0 {
+		return append(out, src...)
+	}
+	strides := make([]int, rank)
+	stride := 1
+	for i := rank - 1; i >= 0; i-- {
+		strides[i] = stride
+		stride *= srcShape[i]
+	}
+	run := shape[rank-1]
+	coord := make([]int, rank-1)
+	for {
+		offset := lo[rank-1]
+		for i, c := range coord {
+			offset += (lo[i] + c) * strides[i]
+		}
+		out = append(out, src[offset:offset+run]...)
+
+		i := rank - 2
+		for ; i >= 0; i-- {
+			coord[i]++
+			if coord[i] < shape[i] {
+				break
+			}
+			coord[i] = 0
+		}
+		if i < 0 {
+			return out
+		}
+	}
+}
+
+// Pad returns a copy of a grown by widths[i] = (before, after) cells on each
+// side of axis i, with the new cells set to value (converted as Fill does).
+// len(widths) must equal the rank and widths must be non-negative.
+func (a *NdArray) Pad(widths [][2]int, value float64) (*NdArray, error) {
+	if len(widths) != len(a.shape) {
+		return nil, fmt.Errorf("got %d pad widths for an array of rank %d", len(widths), len(a.shape))
+	}
+	lo := make([]int, len(a.shape))
+	shape := make([]int, len(a.shape))
+	for i, w := range widths {
+		if w[0] < 0 || w[1] < 0 {
+			return nil, fmt.Errorf("pad widths must be non-negative, got %v for axis %d", w, i)
+		}
+		lo[i], shape[i] = w[0], a.shape[i]+w[0]+w[1]
+	}
+
+	out := ZerosDType(shape, a.dtype)
+	out.Fill(value)
+	switch a.dtype {
+	case Float32:
+		placeData(out.data.([]float32), shape, a.data.([]float32), a.shape, lo)
+	case Bool:
+		placeData(out.data.([]bool), shape, a.data.([]bool), a.shape, lo)
+	case Int64:
+		placeData(out.data.([]int64), shape, a.data.([]int64), a.shape, lo)
+	default:
+		placeData(out.data.([]float64), shape, a.data.([]float64), a.shape, lo)
+	}
+	return out, nil
+}
+
+// placeData copies src (of shape srcShape) into dst (of shape dstShape) with
+// its origin at lo, the inverse of sliceData.
+func placeData[T any](dst []T, dstShape []int, src []T, srcShape, lo []int) {
+	rank := len(srcShape)
+	if len(src) == 0 {
+		return
+	}
+	if rank == 0 {
+		copy(dst, src)
+		return
+	}
+	strides := make([]int, rank)
+	stride := 1
+	for i := rank - 1; i >= 0; i-- {
+		strides[i] = stride
+		stride *= dstShape[i]
+	}
+	run := srcShape[rank-1]
+	coord := make([]int, rank-1)
+	for start := 0; start < len(src); start += run {
+		offset := lo[rank-1]
+		for i, c := range coord {
+			offset += (lo[i] + c) * strides[i]
+		}
+		copy(dst[offset:offset+run], src[start:start+run])
+
+		for i := rank - 2; i >= 0; i-- {
+			coord[i]++
+			if coord[i] < srcShape[i] {
+				break
+			}
+			coord[i] = 0
+		}
+	}
+}
+
+// flatValue returns the numeric element at a row-major flat offset.
+func (a *NdArray) flatValue(offset int) (float64, error) {
+	switch a.dtype {
+	case Float64:
+		return a.data.([]float64)[offset], nil
 	case Float32:
 		return float64(a.data.([]float32)[offset]), nil
+	case Int64:
+		return float64(a.data.([]int64)[offset]), nil
 	default:
 		return 0, errors.New("Get not supported for Bool arrays; use BoolData()")
 	}
 }
 
+// Linspace returns numPoints evenly spaced values from start to stop inclusive.
 func Linspace(start, stop float64, numPoints int) []float64 {
+	return LinspaceEx(start, stop, numPoints, true)
+}
+
+// LinspaceEx returns numPoints evenly spaced values starting at start. When
+// endpoint is false stop is excluded and the spacing is (stop-start)/numPoints,
+// as for a periodic grid.
+func LinspaceEx(start, stop float64, numPoints int, endpoint bool) []float64 {
+	values, _ := LinspaceStep(start, stop, numPoints, endpoint)
+	return values
+}
+
+// LinspaceStep is LinspaceEx that also returns the spacing between values.
+// The step is NaN when it is undefined, i.e. for fewer than two points with
+// the endpoint included or for no points at all.
+func LinspaceStep(start, stop float64, numPoints int, endpoint bool) ([]float64, float64) {
 	if numPoints <= 0 {
-		return nil
+		return nil, math.NaN()
+	}
+	div := numPoints
+	if endpoint {
+		div--
 	}
-	if numPoints == 1 {
-		return []float64{start}
+	if div == 0 {
+		return []float64{start}, math.NaN()
 	}
 
-	step := (stop - start) / float64(numPoints-1)
+	step := (stop - start) / float64(div)
 	result := make([]float64, numPoints)
 	for i := range numPoints {
 		result[i] = start + float64(i)*step
 	}
-	return result
+	return result, step
 }
 
 // --- Aggregation operations (SIMD-backed) ---
 
+// Sum returns the sum of all elements. Float32 data is accumulated in float64
+// to avoid precision loss on long arrays; use SumFast to trade accuracy for speed.
+// Int64 data is summed exactly before conversion.
 func (a *NdArray) Sum() float64 {
+	if a.dtype == Int64 {
+		var sum int64
+		for _, v := range a.data.([]int64) {
+			sum += v
+		}
+		return float64(sum)
+	}
+	if a.dtype == Float32 {
+		sum := 0.0
+		for _, v := range a.data.([]float32) {
+			sum += float64(v)
+		}
+		return sum
+	}
+	return vek.Sum(a.mustFloat64())
+}
+
+// SumFast returns the sum of all elements, accumulating Float32 data in float32
+// with SIMD. Faster than Sum but may lose precision on long Float32 arrays.
+func (a *NdArray) SumFast() float64 {
 	if a.dtype == Float32 {
 		return float64(vek32.Sum(a.data.([]float32)))
 	}
 	return vek.Sum(a.mustFloat64())
 }
 
+// SumAndSumSq returns the sum and the sum of squares of all elements in a
+// single pass, accumulating in float64. Together with the element count they
+// give the variance without a second traversal.
+func (a *NdArray) SumAndSumSq() (sum float64, sumSq float64) {
+	if a.dtype == Float32 {
+		for _, v := range a.data.([]float32) {
+			x := float64(v)
+			sum += x
+			sumSq += x * x
+		}
+		return sum, sumSq
+	}
+	for _, x := range a.mustFloat64() {
+		sum += x
+		sumSq += x * x
+	}
+	return sum, sumSq
+}
+
 func (a *NdArray) Mean() float64 {
 	if a.dtype == Float32 {
 		return float64(vek32.Mean(a.data.([]float32)))
@@ -505,6 +1336,33 @@ func (a *NdArray) Mean() float64 {
 	return vek.Mean(a.mustFloat64())
 }
 
+// Var returns the variance of all elements with ddof delta degrees of
+// freedom: the squared deviations are divided by n-ddof, so ddof=0 gives the
+// population variance and ddof=1 the sample variance. It returns NaN when
+// n <= ddof.
+func (a *NdArray) Var(ddof int) float64 {
+	return varianceDdof(a.mustFloat64(), ddof)
+}
+
+// Std returns the standard deviation of all elements, the square root of
+// Var(ddof).
+func (a *NdArray) Std(ddof int) float64 {
+	return math.Sqrt(a.Var(ddof))
+}
+
+// varianceDdof computes the two-pass variance of x divided by len(x)-ddof.
+func varianceDdof(x []float64, ddof int) float64 {
+	if len(x) <= ddof {
+		return math.NaN()
+	}
+	mean := vek.Mean(x)
+	var ss float64
+	for _, v := range x {
+		ss += (v - mean) * (v - mean)
+	}
+	return ss / float64(len(x)-ddof)
+}
+
 func (a *NdArray) Min() float64 {
 	if a.dtype == Float32 {
 		return float64(vek32.Min(a.data.([]float32)))
@@ -526,8 +1384,9 @@ func (a *NdArray) Prod() float64 {
 	return vek.Prod(a.mustFloat64())
 }
 
-// Median returns the median value.
-func (a *NdArray) Median() float64 {
+// MedianAll returns the median of all elements; use Median for a reduction
+// along an axis.
+func (a *NdArray) MedianAll() float64 {
 	if a.dtype == Float32 {
 		return float64(vek32.Median(a.data.([]float32)))
 	}
@@ -610,6 +1469,101 @@ func (a *NdArray) Ceil() *NdArray {
 	return &NdArray{shape: a.shape, data: vek.Ceil(a.mustFloat64()), dtype: Float64}
 }
 
+// Clip returns a copy with each element clamped into [lo, hi]. Use
+// math.Inf(-1) or math.Inf(1) to leave a side unbounded. Int64 input gives
+// Float64. It panics if lo > hi or a is a Bool array.
+func (a *NdArray) Clip(lo, hi float64) *NdArray {
+	out := a.floatCopy("Clip")
+	out.ClipInPlace(lo, hi)
+	return out
+}
+
+// HardThreshold zeroes out elements with |x| < t (the L0 proximal operator).
+// Int64 input gives Float64.
+func (a *NdArray) HardThreshold(t float64) *NdArray {
+	out := a.floatCopy("HardThreshold")
+	out.HardThresholdInPlace(t)
+	return out
+}
+
+// SnapTo rounds each element to the nearest multiple of step. Int64 input
+// gives Float64. It panics if step is not positive.
+func (a *NdArray) SnapTo(step float64) *NdArray {
+	out := a.floatCopy("SnapTo")
+	out.SnapToInPlace(step)
+	return out
+}
+
+// SoftThreshold shrinks each element toward zero by t, clamping at zero
+// (the L1 proximal operator): sign(x) * max(|x|-t, 0). NaN elements stay NaN
+// and Int64 input gives Float64. It panics if t is negative.
+func (a *NdArray) SoftThreshold(t float64) *NdArray {
+	out := a.floatCopy("SoftThreshold")
+	out.SoftThresholdInPlace(t)
+	return out
+}
+
+// Replace returns a copy with every element equal to from substituted by to.
+// A NaN from matches NaN elements. Int64 input gives Float64.
+func (a *NdArray) Replace(from, to float64) *NdArray {
+	out := a.floatCopy("Replace")
+	out.ReplaceInPlace(from, to)
+	return out
+}
+
+// ReplaceClose returns a copy with every element within tol of from
+// substituted by to. A NaN from matches NaN elements. Int64 input gives
+// Float64.
+func (a *NdArray) ReplaceClose(from, to, tol float64) *NdArray {
+	out := a.floatCopy("ReplaceClose")
+	out.replaceClose(from, to, tol)
+	return out
+}
+
+// floatCopy returns a copy of a for the float-only element-wise ops above,
+// widening Int64 to Float64 since their in-place bodies handle float data only.
+// It panics for Bool arrays, naming op in the message.
+func (a *NdArray) floatCopy(op string) *NdArray {
+	if a.dtype == Bool {
+		panic(op + " not supported for Bool arrays")
+	}
+	if a.dtype == Int64 {
+		return fromFloat64(slices.Clone(a.shape), a.mustFloat64(), Float64)
+	}
+	return a.Copy()
+}
+
+// MaskBySign zeroes elements of a where the sign of ref (broadcast to a's
+// shape) does not match keep, which is one of "positive" (ref > 0),
+// "negative" (ref < 0) or "nonneg" (ref >= 0).
+func (a *NdArray) MaskBySign(ref *NdArray, keep string) (*NdArray, error) {
+	var match func(float64) bool
+	switch keep {
+	case "positive":
+		match = func(r float64) bool { return r > 0 }
+	case "negative":
+		match = func(r float64) bool { return r < 0 }
+	case "nonneg":
+		match = func(r float64) bool { return r >= 0 }
+	default:
+		return nil, fmt.Errorf("invalid keep %q: must be \"positive\", \"negative\" or \"nonneg\"", keep)
+	}
+	if a.dtype == Bool {
+		return nil, errors.New("MaskBySign not supported for Bool arrays")
+	}
+	out := a.Copy()
+	err := out.ApplyOpInPlace(ref, func(x, r float64) float64 {
+		if match(r) {
+			return x
+		}
+		return 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // --- Transcendental functions (vek32 SIMD-backed for Float32, math stdlib for Float64) ---
 
 // Sin computes element-wise sine.
@@ -638,10 +1592,67 @@ func (a *NdArray) Cos() *NdArray {
 	return &NdArray{shape: a.shape, data: out, dtype: Float64}
 }
 
+// Tan computes element-wise tangent.
+func (a *NdArray) Tan() *NdArray {
+	return a.mapFloat(math.Tan)
+}
+
+// Asin computes element-wise arcsine; values outside [-1, 1] give NaN.
+func (a *NdArray) Asin() *NdArray {
+	return a.mapFloat(math.Asin)
+}
+
+// Acos computes element-wise arccosine; values outside [-1, 1] give NaN.
+func (a *NdArray) Acos() *NdArray {
+	return a.mapFloat(math.Acos)
+}
+
+// Atan computes element-wise arctangent.
+func (a *NdArray) Atan() *NdArray {
+	return a.mapFloat(math.Atan)
+}
+
+// mapFloat applies fn to every element in float64 and returns a new array of
+// the same dtype.
+func (a *NdArray) mapFloat(fn func(float64) float64) *NdArray {
+	if a.dtype == Float32 {
+		src := a.data.([]float32)
+		out := make([]float32, len(src))
+		for i, v := range src {
+			out[i] = float32(fn(float64(v)))
+		}
+		return &NdArray{shape: a.shape, data: out, dtype: Float32}
+	}
+	d := a.mustFloat64()
+	out := make([]float64, len(d))
+	for i, v := range d {
+		out[i] = fn(v)
+	}
+	return &NdArray{shape: a.shape, data: out, dtype: Float64}
+}
+
+// SinCos computes element-wise sine and cosine in a single traversal, both
+// in the array's dtype.
+func (a *NdArray) SinCos() (sin *NdArray, cos *NdArray) {
+	if a.dtype == Float32 {
+		src := a.data.([]float32)
+		s, c := make([]float32, len(src)), make([]float32, len(src))
+		vek32.SinCos_Into(s, c, src)
+		return &NdArray{shape: a.shape, data: s, dtype: Float32}, &NdArray{shape: a.shape, data: c, dtype: Float32}
+	}
+	d := a.mustFloat64()
+	s, c := make([]float64, len(d)), make([]float64, len(d))
+	for i, v := range d {
+		s[i], c[i] = math.Sincos(v)
+	}
+	return &NdArray{shape: a.shape, data: s, dtype: Float64}, &NdArray{shape: a.shape, data: c, dtype: Float64}
+}
+
 // Exp computes element-wise exponential (e^x).
 func (a *NdArray) Exp() *NdArray {
 	if a.dtype == Float32 {
-		return &NdArray{shape: a.shape, data: vek32.Exp(a.data.([]float32)), dtype: Float32}
+		src := a.data.([]float32)
+		return &NdArray{shape: a.shape, data: fixExp32(src, vek32.Exp(src), math.Exp), dtype: Float32}
 	}
 	d := a.mustFloat64()
 	out := make([]float64, len(d))
@@ -654,7 +1665,8 @@ func (a *NdArray) Exp() *NdArray {
 // Log computes element-wise natural logarithm.
 func (a *NdArray) Log() *NdArray {
 	if a.dtype == Float32 {
-		return &NdArray{shape: a.shape, data: vek32.Log(a.data.([]float32)), dtype: Float32}
+		src := a.data.([]float32)
+		return &NdArray{shape: a.shape, data: fixLog32(src, vek32.Log(src), math.Log), dtype: Float32}
 	}
 	d := a.mustFloat64()
 	out := make([]float64, len(d))
@@ -664,10 +1676,20 @@ func (a *NdArray) Log() *NdArray {
 	return &NdArray{shape: a.shape, data: out, dtype: Float64}
 }
 
+// SafeLog computes log(max(x, eps)) element-wise, so zeros map to log(eps)
+// instead of -Inf. Float dtypes are preserved and Int64 input gives Float64.
+// It panics if eps is not positive or a is a Bool array.
+func (a *NdArray) SafeLog(eps float64) *NdArray {
+	out := a.floatCopy("SafeLog")
+	out.SafeLogInPlace(eps)
+	return out
+}
+
 // Log2 computes element-wise base-2 logarithm.
 func (a *NdArray) Log2() *NdArray {
 	if a.dtype == Float32 {
-		return &NdArray{shape: a.shape, data: vek32.Log2(a.data.([]float32)), dtype: Float32}
+		src := a.data.([]float32)
+		return &NdArray{shape: a.shape, data: fixLog32(src, vek32.Log2(src), math.Log2), dtype: Float32}
 	}
 	d := a.mustFloat64()
 	out := make([]float64, len(d))
@@ -680,7 +1702,8 @@ func (a *NdArray) Log2() *NdArray {
 // Log10 computes element-wise base-10 logarithm.
 func (a *NdArray) Log10() *NdArray {
 	if a.dtype == Float32 {
-		return &NdArray{shape: a.shape, data: vek32.Log10(a.data.([]float32)), dtype: Float32}
+		src := a.data.([]float32)
+		return &NdArray{shape: a.shape, data: fixLog32(src, vek32.Log10(src), math.Log10), dtype: Float32}
 	}
 	d := a.mustFloat64()
 	out := make([]float64, len(d))
@@ -690,6 +1713,37 @@ func (a *NdArray) Log10() *NdArray {
 	return &NdArray{shape: a.shape, data: out, dtype: Float64}
 }
 
+// float32 range limits for the SIMD exp and log kernels, which are only
+// accurate for normal, finite results.
+const (
+	minNormal32 = 0x1p-126
+	maxExpArg32 = 88.72283
+	minExpArg32 = -87.33654
+)
+
+// fixExp32 recomputes out = exp(src) with fn wherever the SIMD kernel
+// saturates instead of returning +Inf, 0 or NaN.
+func fixExp32(src, out []float32, fn func(float64) float64) []float32 {
+	for i, v := range src {
+		if !(v > minExpArg32 && v < maxExpArg32) {
+			out[i] = float32(fn(float64(v)))
+		}
+	}
+	return out
+}
+
+// fixLog32 recomputes out = log(src) with fn for zero, negative, subnormal,
+// infinite and NaN inputs, where the SIMD kernel returns garbage rather than
+// -Inf or NaN.
+func fixLog32(src, out []float32, fn func(float64) float64) []float32 {
+	for i, v := range src {
+		if !(v >= minNormal32 && v <= math.MaxFloat32) {
+			out[i] = float32(fn(float64(v)))
+		}
+	}
+	return out
+}
+
 // --- Cumulative operations (SIMD-backed) ---
 
 func (a *NdArray) CumSum() *NdArray {
@@ -708,13 +1762,17 @@ func (a *NdArray) CumProd() *NdArray {
 
 // --- Vector operations (SIMD-backed) ---
 
-// Dot computes the dot product of two 1-D arrays.
+// Dot computes the dot product of two 1-D arrays. When both are Float32 the
+// product is accumulated in float32.
 func Dot(a, b *NdArray) (float64, error) {
 	if len(a.shape) != 1 || len(b.shape) != 1 {
-		return 0, errors.New("Dot requires 1-D arrays")
+		return 0, fmt.Errorf("Dot requires 1-D arrays, got shapes %v and %v", a.shape, b.shape)
 	}
 	if a.shape[0] != b.shape[0] {
-		return 0, errors.New("Dot requires arrays of equal length")
+		return 0, fmt.Errorf("Dot requires arrays of equal length, got %d and %d", a.shape[0], b.shape[0])
+	}
+	if a.dtype == Bool || b.dtype == Bool {
+		return 0, errors.New("Dot not supported for Bool arrays")
 	}
 	if a.dtype == Float32 && b.dtype == Float32 {
 		return float64(vek32.Dot(a.data.([]float32), b.data.([]float32))), nil
@@ -771,146 +1829,222 @@ func CosineSimilarity(a, b *NdArray) (float64, error) {
 	return vek.CosineSimilarity(a.mustFloat64(), b.mustFloat64()), nil
 }
 
-// --- Comparison operations (SIMD-backed) ---
-
-// Eq performs element-wise equality comparison.
-func Eq(a, b *NdArray) (*NdArray, error) {
-	if !shapesEqual(a.shape, b.shape) {
-		return nil, errors.New("broadcasting not yet supported for boolean ops")
+// AllClose reports whether a and b, broadcast together, are element-wise equal
+// within tolerance: |a-b| <= atol + rtol*|b|. NaNs compare equal to NaNs in
+// the same position.
+func AllClose(a, b *NdArray, rtol, atol float64) (bool, error) {
+	err := a.AssertAllClose(b, rtol, atol)
+	if _, mismatch := err.(*closeError); mismatch {
+		return false, nil
 	}
-	size := ProdInt(a.shape)
-	data := make([]bool, size)
+	return err == nil, err
+}
 
-	if a.dtype == Float64 && b.dtype == Float64 {
-		vek.Eq_Into(data, a.data.([]float64), b.data.([]float64))
-	} else if a.dtype == Float32 && b.dtype == Float32 {
-		vek32.Eq_Into(data, a.data.([]float32), b.data.([]float32))
-	} else if a.dtype == Bool && b.dtype == Bool {
-		aData := a.data.([]bool)
-		bData := b.data.([]bool)
-		for i := range size {
-			data[i] = aData[i] == bData[i]
-		}
-	} else {
-		if a.dtype != Bool && b.dtype != Bool {
-			vek.Eq_Into(data, a.mustFloat64(), b.mustFloat64())
-		} else {
-			return nil, errors.New("cannot compare boolean with numeric type")
+// AssertAllClose returns nil when a and b satisfy AllClose and otherwise an
+// error naming the first differing coordinate and both values.
+func (a *NdArray) AssertAllClose(b *NdArray, rtol, atol float64) error {
+	aIdx, bIdx, outShape, err := BroadcastIndices(a.shape, b.shape)
+	if err != nil {
+		return err
+	}
+	aData, err := a.toFloat64()
+	if err != nil {
+		return err
+	}
+	bData, err := b.toFloat64()
+	if err != nil {
+		return err
+	}
+	for i := range aIdx {
+		x, y := aData[aIdx[i]], bData[bIdx[i]]
+		if x == y || (math.IsNaN(x) && math.IsNaN(y)) || math.Abs(x-y) <= atol+rtol*math.Abs(y) {
+			continue
 		}
+		return &closeError{index: unravelIndex(i, outShape), a: x, b: y}
 	}
-	return &NdArray{shape: a.shape, data: data, dtype: Bool}, nil
+	return nil
 }
 
-// Neq performs element-wise non-equality comparison.
-func Neq(a, b *NdArray) (*NdArray, error) {
-	if !shapesEqual(a.shape, b.shape) {
-		return nil, errors.New("broadcasting not yet supported for boolean ops")
-	}
-	size := ProdInt(a.shape)
-	data := make([]bool, size)
+// closeError describes the first mismatch found by AssertAllClose.
+type closeError struct {
+	index []int
+	a, b  float64
+}
 
-	if a.dtype == Float64 && b.dtype == Float64 {
-		vek.Neq_Into(data, a.data.([]float64), b.data.([]float64))
-	} else if a.dtype == Float32 && b.dtype == Float32 {
-		vek32.Neq_Into(data, a.data.([]float32), b.data.([]float32))
-	} else if a.dtype == Bool && b.dtype == Bool {
-		aData := a.data.([]bool)
-		bData := b.data.([]bool)
-		for i := range size {
-			data[i] = aData[i] != bData[i]
-		}
-	} else {
-		if a.dtype != Bool && b.dtype != Bool {
-			vek.Neq_Into(data, a.mustFloat64(), b.mustFloat64())
-		} else {
-			return nil, errors.New("cannot compare boolean with numeric type")
-		}
+func (e *closeError) Error() string {
+	return fmt.Sprintf("arrays differ at %v: %v != %v", e.index, e.a, e.b)
+}
+
+// unravelIndex converts a row-major flat index into coordinates within shape.
+func unravelIndex(flat int, shape []int) []int {
+	coord := make([]int, len(shape))
+	for ax := len(shape) - 1; ax >= 0; ax-- {
+		coord[ax] = flat % shape[ax]
+		flat /= shape[ax]
 	}
-	return &NdArray{shape: a.shape, data: data, dtype: Bool}, nil
+	return coord
+}
+
+// --- Comparison operations (SIMD-backed) ---
+
+// Eq performs element-wise equality comparison, broadcasting a and b.
+func Eq(a, b *NdArray) (*NdArray, error) {
+	return compare(a, b, EqInto)
+}
+
+// Neq performs element-wise non-equality comparison, broadcasting a and b.
+func Neq(a, b *NdArray) (*NdArray, error) {
+	return compare(a, b, NeqInto)
 }
 
-// Lt performs element-wise less than comparison.
+// Lt performs element-wise less than comparison, broadcasting a and b.
 func Lt(a, b *NdArray) (*NdArray, error) {
+	return compare(a, b, LtInto)
+}
+
+// Lte performs element-wise less than or equal comparison, broadcasting a and b.
+func Lte(a, b *NdArray) (*NdArray, error) {
+	return compare(a, b, LteInto)
+}
+
+// Gt performs element-wise greater than comparison, broadcasting a and b.
+func Gt(a, b *NdArray) (*NdArray, error) {
+	return compare(a, b, GtInto)
+}
+
+// Gte performs element-wise greater than or equal comparison, broadcasting a and b.
+func Gte(a, b *NdArray) (*NdArray, error) {
+	return compare(a, b, GteInto)
+}
+
+// EqInto writes the element-wise equality of a and b into out, which must
+// have one entry per element.
+func EqInto(out []bool, a, b *NdArray) error {
+	return compareInto(out, a, b, vek.Eq_Into, vek32.Eq_Into, func(x, y bool) bool { return x == y })
+}
+
+// NeqInto writes the element-wise non-equality of a and b into out.
+func NeqInto(out []bool, a, b *NdArray) error {
+	return compareInto(out, a, b, vek.Neq_Into, vek32.Neq_Into, func(x, y bool) bool { return x != y })
+}
+
+// LtInto writes the element-wise a < b into out.
+func LtInto(out []bool, a, b *NdArray) error {
+	return compareInto(out, a, b, vek.Lt_Into, vek32.Lt_Into, nil)
+}
+
+// LteInto writes the element-wise a <= b into out.
+func LteInto(out []bool, a, b *NdArray) error {
+	return compareInto(out, a, b, vek.Lte_Into, vek32.Lte_Into, nil)
+}
+
+// GtInto writes the element-wise a > b into out.
+func GtInto(out []bool, a, b *NdArray) error {
+	return compareInto(out, a, b, vek.Gt_Into, vek32.Gt_Into, nil)
+}
+
+// GteInto writes the element-wise a >= b into out.
+func GteInto(out []bool, a, b *NdArray) error {
+	return compareInto(out, a, b, vek.Gte_Into, vek32.Gte_Into, nil)
+}
+
+// compare allocates a Bool result and fills it with into. Operands of
+// different shapes are first expanded to their broadcast shape via
+// BroadcastIndices, so the comparison kernels only ever see equal shapes.
+func compare(a, b *NdArray, into func(out []bool, a, b *NdArray) error) (*NdArray, error) {
 	if !shapesEqual(a.shape, b.shape) {
-		return nil, errors.New("broadcasting not yet supported for boolean ops")
+		aIdx, bIdx, shape, err := BroadcastIndices(a.shape, b.shape)
+		if err != nil {
+			return nil, err
+		}
+		a, b = a.gather(shape, aIdx), b.gather(shape, bIdx)
 	}
 	data := make([]bool, ProdInt(a.shape))
-
-	if a.dtype == Float64 && b.dtype == Float64 {
-		vek.Lt_Into(data, a.data.([]float64), b.data.([]float64))
-	} else if a.dtype == Float32 && b.dtype == Float32 {
-		vek32.Lt_Into(data, a.data.([]float32), b.data.([]float32))
-	} else {
-		if a.dtype != Bool && b.dtype != Bool {
-			vek.Lt_Into(data, a.mustFloat64(), b.mustFloat64())
-		} else {
-			return nil, errors.New("cannot compare boolean with numeric type")
-		}
+	if err := into(data, a, b); err != nil {
+		return nil, err
 	}
 	return &NdArray{shape: a.shape, data: data, dtype: Bool}, nil
 }
 
-// Lte performs element-wise less than or equal comparison.
-func Lte(a, b *NdArray) (*NdArray, error) {
+// compareInto validates shapes and dispatches on dtype. boolOp handles
+// Bool-Bool comparisons; when nil, Bool operands are rejected. The Into
+// variants write into a caller-sized buffer, so they require equal shapes.
+func compareInto(out []bool, a, b *NdArray,
+	f64 func(dst []bool, x, y []float64) []bool,
+	f32 func(dst []bool, x, y []float32) []bool,
+	boolOp func(x, y bool) bool,
+) error {
 	if !shapesEqual(a.shape, b.shape) {
-		return nil, errors.New("broadcasting not yet supported for boolean ops")
+		return fmt.Errorf("Into comparisons require equal shapes, got %v and %v; use the allocating form to broadcast", a.shape, b.shape)
+	}
+	if size := ProdInt(a.shape); len(out) != size {
+		return fmt.Errorf("output length %d does not match element count %d", len(out), size)
 	}
-	data := make([]bool, ProdInt(a.shape))
 
-	if a.dtype == Float64 && b.dtype == Float64 {
-		vek.Lte_Into(data, a.data.([]float64), b.data.([]float64))
-	} else if a.dtype == Float32 && b.dtype == Float32 {
-		vek32.Lte_Into(data, a.data.([]float32), b.data.([]float32))
-	} else {
-		if a.dtype != Bool && b.dtype != Bool {
-			vek.Lte_Into(data, a.mustFloat64(), b.mustFloat64())
-		} else {
-			return nil, errors.New("cannot compare boolean with numeric type")
+	switch {
+	case a.dtype == Float64 && b.dtype == Float64:
+		f64(out, a.data.([]float64), b.data.([]float64))
+	case a.dtype == Float32 && b.dtype == Float32:
+		f32(out, a.data.([]float32), b.data.([]float32))
+	case a.dtype == Bool && b.dtype == Bool && boolOp != nil:
+		aData, bData := a.data.([]bool), b.data.([]bool)
+		for i := range out {
+			out[i] = boolOp(aData[i], bData[i])
 		}
+	case a.dtype != Bool && b.dtype != Bool:
+		f64(out, a.mustFloat64(), b.mustFloat64())
+	default:
+		return errors.New("cannot compare boolean with numeric type")
 	}
-	return &NdArray{shape: a.shape, data: data, dtype: Bool}, nil
+	return nil
 }
 
-// Gt performs element-wise greater than comparison.
-func Gt(a, b *NdArray) (*NdArray, error) {
-	if !shapesEqual(a.shape, b.shape) {
-		return nil, errors.New("broadcasting not yet supported for boolean ops")
+// EqScalar returns a Bool mask of elements equal to s.
+func (a *NdArray) EqScalar(s float64) *NdArray {
+	if a.dtype == Float32 {
+		return &NdArray{shape: a.shape, data: vek32.EqNumber(a.data.([]float32), float32(s)), dtype: Bool}
 	}
-	data := make([]bool, ProdInt(a.shape))
+	return &NdArray{shape: a.shape, data: vek.EqNumber(a.mustFloat64(), s), dtype: Bool}
+}
 
-	if a.dtype == Float64 && b.dtype == Float64 {
-		vek.Gt_Into(data, a.data.([]float64), b.data.([]float64))
-	} else if a.dtype == Float32 && b.dtype == Float32 {
-		vek32.Gt_Into(data, a.data.([]float32), b.data.([]float32))
-	} else {
-		if a.dtype != Bool && b.dtype != Bool {
-			vek.Gt_Into(data, a.mustFloat64(), b.mustFloat64())
-		} else {
-			return nil, errors.New("cannot compare boolean with numeric type")
-		}
+// NeScalar returns a Bool mask of elements not equal to s.
+func (a *NdArray) NeScalar(s float64) *NdArray {
+	if a.dtype == Float32 {
+		return &NdArray{shape: a.shape, data: vek32.NeqNumber(a.data.([]float32), float32(s)), dtype: Bool}
 	}
-	return &NdArray{shape: a.shape, data: data, dtype: Bool}, nil
+	return &NdArray{shape: a.shape, data: vek.NeqNumber(a.mustFloat64(), s), dtype: Bool}
 }
 
-// Gte performs element-wise greater than or equal comparison.
-func Gte(a, b *NdArray) (*NdArray, error) {
-	if !shapesEqual(a.shape, b.shape) {
-		return nil, errors.New("broadcasting not yet supported for boolean ops")
+// LtScalar returns a Bool mask of elements less than s.
+func (a *NdArray) LtScalar(s float64) *NdArray {
+	if a.dtype == Float32 {
+		return &NdArray{shape: a.shape, data: vek32.LtNumber(a.data.([]float32), float32(s)), dtype: Bool}
 	}
-	data := make([]bool, ProdInt(a.shape))
+	return &NdArray{shape: a.shape, data: vek.LtNumber(a.mustFloat64(), s), dtype: Bool}
+}
 
-	if a.dtype == Float64 && b.dtype == Float64 {
-		vek.Gte_Into(data, a.data.([]float64), b.data.([]float64))
-	} else if a.dtype == Float32 && b.dtype == Float32 {
-		vek32.Gte_Into(data, a.data.([]float32), b.data.([]float32))
-	} else {
-		if a.dtype != Bool && b.dtype != Bool {
-			vek.Gte_Into(data, a.mustFloat64(), b.mustFloat64())
-		} else {
-			return nil, errors.New("cannot compare boolean with numeric type")
-		}
+// LeScalar returns a Bool mask of elements less than or equal to s.
+func (a *NdArray) LeScalar(s float64) *NdArray {
+	if a.dtype == Float32 {
+		return &NdArray{shape: a.shape, data: vek32.LteNumber(a.data.([]float32), float32(s)), dtype: Bool}
 	}
-	return &NdArray{shape: a.shape, data: data, dtype: Bool}, nil
+	return &NdArray{shape: a.shape, data: vek.LteNumber(a.mustFloat64(), s), dtype: Bool}
+}
+
+// GtScalar returns a Bool mask of elements greater than s.
+func (a *NdArray) GtScalar(s float64) *NdArray {
+	if a.dtype == Float32 {
+		return &NdArray{shape: a.shape, data: vek32.GtNumber(a.data.([]float32), float32(s)), dtype: Bool}
+	}
+	return &NdArray{shape: a.shape, data: vek.GtNumber(a.mustFloat64(), s), dtype: Bool}
+}
+
+// GeScalar returns a Bool mask of elements greater than or equal to s.
+func (a *NdArray) GeScalar(s float64) *NdArray {
+	if a.dtype == Float32 {
+		return &NdArray{shape: a.shape, data: vek32.GteNumber(a.data.([]float32), float32(s)), dtype: Bool}
+	}
+	return &NdArray{shape: a.shape, data: vek.GteNumber(a.mustFloat64(), s), dtype: Bool}
 }
 
 // --- Boolean operations (SIMD-backed) ---
@@ -1017,9 +2151,178 @@ func Select(a *NdArray, mask *NdArray) (*NdArray, error) {
 	return &NdArray{shape: []int{len(result)}, data: result, dtype: Float64}, nil
 }
 
+// MaskedSelect returns a 1-D array of the elements of a where the Bool array
+// mask, which must have exactly a's shape, is true, in row-major order. The
+// dtype is preserved. Select is the looser form that only matches sizes.
+func (a *NdArray) MaskedSelect(mask *NdArray) (*NdArray, error) {
+	if err := a.checkMask(mask); err != nil {
+		return nil, err
+	}
+	m := mask.data.([]bool)
+	var data any
+	switch a.dtype {
+	case Float32:
+		data = maskedData(a.data.([]float32), m)
+	case Bool:
+		data = maskedData(a.data.([]bool), m)
+	case Int64:
+		data = maskedData(a.data.([]int64), m)
+	default:
+		data = maskedData(a.data.([]float64), m)
+	}
+	return &NdArray{shape: []int{vek.Count(m)}, data: data, dtype: a.dtype}, nil
+}
+
+// MaskedAssign sets every element of a where mask is true to value,
+// converting it to a's numeric dtype as Set does.
+func (a *NdArray) MaskedAssign(mask *NdArray, value float64) error {
+	if err := a.checkMask(mask); err != nil {
+		return err
+	}
+	m := mask.data.([]bool)
+	switch a.dtype {
+	case Float64:
+		maskedFill(a.data.([]float64), m, value)
+	case Float32:
+		maskedFill(a.data.([]float32), m, float32(value))
+	case Int64:
+		maskedFill(a.data.([]int64), m, int64(value))
+	default:
+		return errors.New("MaskedAssign not supported for Bool arrays")
+	}
+	return nil
+}
+
+func (a *NdArray) checkMask(mask *NdArray) error {
+	if mask.dtype != Bool {
+		return errors.New("mask must be a Bool array")
+	}
+	if !shapesEqual(a.shape, mask.shape) {
+		return fmt.Errorf("mask shape %v does not match array shape %v", mask.shape, a.shape)
+	}
+	return nil
+}
+
+func maskedData[T any](data []T, mask []bool) []T {
+	out := make([]T, 0, len(data))
+	for i, keep := range mask {
+		if keep {
+			out = append(out, data[i])
+		}
+	}
+	return out
+}
+
+func maskedFill[T any](data []T, mask []bool, value T) {
+	for i, set := range mask {
+		if set {
+			data[i] = value
+		}
+	}
+}
+
+// IndexRows gathers rows of the 2-D array a selected by the integer-valued
+// 1-D array idx, returning a [len(idx), cols] array of a's dtype. Negative
+// indices count from the last row.
+func (a *NdArray) IndexRows(idx *NdArray) (*NdArray, error) {
+	if len(a.shape) != 2 {
+		return nil, errors.New("IndexRows requires a 2-D array")
+	}
+	if len(idx.shape) != 1 {
+		return nil, errors.New("IndexRows requires a 1-D index array")
+	}
+	idxData, err := idx.toFloat64()
+	if err != nil {
+		return nil, err
+	}
+	rows, cols := a.shape[0], a.shape[1]
+	rowIdx := make([]int, len(idxData))
+	for i, v := range idxData {
+		r := int(v)
+		if float64(r) != v {
+			return nil, fmt.Errorf("index %v at position %d is not an integer", v, i)
+		}
+		if r < 0 {
+			r += rows
+		}
+		if r < 0 || r >= rows {
+			return nil, fmt.Errorf("row index %v out of range for %d rows", v, rows)
+		}
+		rowIdx[i] = r
+	}
+
+	shape := []int{len(rowIdx), cols}
+	switch a.dtype {
+	case Float32:
+		return &NdArray{shape: shape, data: gatherRows(a.data.([]float32), rowIdx, cols), dtype: Float32}, nil
+	case Bool:
+		return &NdArray{shape: shape, data: gatherRows(a.data.([]bool), rowIdx, cols), dtype: Bool}, nil
+	case Int64:
+		return &NdArray{shape: shape, data: gatherRows(a.data.([]int64), rowIdx, cols), dtype: Int64}, nil
+	default:
+		return &NdArray{shape: shape, data: gatherRows(a.data.([]float64), rowIdx, cols), dtype: Float64}, nil
+	}
+}
+
+// gatherRows copies the given rows of a row-major matrix with cols columns.
+func gatherRows[T any](src []T, rows []int, cols int) []T {
+	out := make([]T, 0, len(rows)*cols)
+	for _, r := range rows {
+		out = append(out, src[r*cols:(r+1)*cols]...)
+	}
+	return out
+}
+
+// PadStack stacks 1-D arrays of varying length into a [len(arrays), maxLen]
+// array, right-padding shorter rows with padValue. The result is Float32 when
+// every input is Float32 and Float64 otherwise.
+func PadStack(arrays []*NdArray, padValue float64) (*NdArray, error) {
+	out, _, err := PadStackMask(arrays, padValue)
+	return out, err
+}
+
+// PadStackMask is PadStack that also returns a Bool mask of the same shape
+// that is true at positions holding input values and false at padding.
+func PadStackMask(arrays []*NdArray, padValue float64) (stacked *NdArray, mask *NdArray, err error) {
+	if len(arrays) == 0 {
+		return nil, nil, errors.New("PadStack requires at least one array")
+	}
+	maxLen, dtype := 0, Float32
+	for i, arr := range arrays {
+		if len(arr.shape) != 1 {
+			return nil, nil, fmt.Errorf("PadStack requires 1-D arrays, array %d has shape %v", i, arr.shape)
+		}
+		if arr.dtype == Bool {
+			return nil, nil, fmt.Errorf("PadStack does not support Bool arrays (array %d)", i)
+		}
+		if arr.dtype != Float32 {
+			dtype = Float64
+		}
+		maxLen = max(maxLen, arr.shape[0])
+	}
+
+	data := make([]float64, len(arrays)*maxLen)
+	valid := make([]bool, len(data))
+	for i, arr := range arrays {
+		row := data[i*maxLen : (i+1)*maxLen]
+		vals, _ := arr.toFloat64()
+		n := copy(row, vals)
+		for j := n; j < maxLen; j++ {
+			row[j] = padValue
+		}
+		for j := range n {
+			valid[i*maxLen+j] = true
+		}
+	}
+	shape := []int{len(arrays), maxLen}
+	return fromFloat64(shape, data, dtype), &NdArray{shape: []int{len(arrays), maxLen}, data: valid, dtype: Bool}, nil
+}
+
 // --- Utility methods ---
 
-// Copy returns a deep copy of the NdArray.
+// Copy returns a deep copy of the NdArray. The copy owns fresh data and shape
+// slices, so it is safe to mutate in place even when a is a view produced by
+// Reshape or InsertAxis.
 func (a *NdArray) Copy() *NdArray {
 	shapeCopy := make([]int, len(a.shape))
 	copy(shapeCopy, a.shape)
@@ -1035,6 +2338,11 @@ func (a *NdArray) Copy() *NdArray {
 		dst := make([]float32, len(src))
 		copy(dst, src)
 		return &NdArray{shape: shapeCopy, data: dst, dtype: Float32}
+	case Int64:
+		src := a.data.([]int64)
+		dst := make([]int64, len(src))
+		copy(dst, src)
+		return &NdArray{shape: shapeCopy, data: dst, dtype: Int64}
 	default:
 		src := a.data.([]bool)
 		dst := make([]bool, len(src))
@@ -1043,69 +2351,228 @@ func (a *NdArray) Copy() *NdArray {
 	}
 }
 
-// String returns a human-readable representation of the NdArray.
+// CopyTo copies a's elements into dst, broadcasting a to dst's shape and
+// converting between numeric dtypes as needed (values copied into an Int64
+// array are truncated toward zero). Bool arrays may only be copied into Bool
+// arrays.
+func (a *NdArray) CopyTo(dst *NdArray) error {
+	bShape, err := broadcastShapes(a.shape, dst.shape)
+	if err != nil || !shapesEqual(bShape, dst.shape) {
+		return fmt.Errorf("cannot broadcast shape %v into %v", a.shape, dst.shape)
+	}
+	if (a.dtype == Bool) != (dst.dtype == Bool) {
+		return errors.New("cannot copy between boolean and numeric arrays")
+	}
+
+	srcIdx, _, _, err := BroadcastIndices(a.shape, dst.shape)
+	if err != nil {
+		return err
+	}
+	for i, src := range srcIdx {
+		switch dst.dtype {
+		case Float64:
+			v, _ := a.flatValue(src)
+			dst.data.([]float64)[i] = v
+		case Float32:
+			v, _ := a.flatValue(src)
+			dst.data.([]float32)[i] = float32(v)
+		case Int64:
+			if a.dtype == Int64 {
+				dst.data.([]int64)[i] = a.data.([]int64)[src]
+			} else {
+				v, _ := a.flatValue(src)
+				dst.data.([]int64)[i] = int64(v)
+			}
+		case Bool:
+			dst.data.([]bool)[i] = a.data.([]bool)[src]
+		}
+	}
+	return nil
+}
+
+// Printing limits for String: arrays with more than printThreshold elements
+// are summarized, showing printEdgeItems entries from each end of every axis.
+const (
+	printThreshold = 1000
+	printEdgeItems = 3
+)
+
+// String returns a human-readable representation of the NdArray: a header
+// line with the shape and dtype followed by the data nested with one bracket
+// level per dimension, e.g.
+//
+//	NdArray(shape=[2 3], dtype=float64)
+//	[[1, 2, 3],
+//	 [4, 5, 6]]
+//
+// Large arrays are summarized with "..." along each axis.
 func (a *NdArray) String() string {
-	var dtypeStr string
-	switch a.dtype {
-	case Float64:
-		dtypeStr = "float64"
-	case Float32:
-		dtypeStr = "float32"
-	case Bool:
-		dtypeStr = "bool"
+	return a.FormatString(-1, printThreshold)
+}
+
+// FormatString renders the array like String but with explicit control over
+// the output. precision is the number of digits after the decimal point, or
+// negative for the shortest exact representation. When the array has more
+// than maxElems elements each axis is truncated to its leading and trailing
+// entries (up to three each); non-positive maxElems shows every element.
+func (a *NdArray) FormatString(precision int, maxElems int) string {
+	return a.render(maxElems, func(i int) string { return a.formatElem(i, precision) })
+}
+
+// Format implements fmt.Formatter. The float verbs (%e, %E, %f, %F, %g, %G)
+// together with their width, precision and flags control how each element is
+// rendered, e.g. fmt.Printf("%.3g", arr). %v and %s use the String layout.
+func (a *NdArray) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		io.WriteString(f, a.String())
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		spec := fmt.FormatString(f, verb)
+		io.WriteString(f, a.render(printThreshold, func(i int) string {
+			switch a.dtype {
+			case Float64:
+				return fmt.Sprintf(spec, a.data.([]float64)[i])
+			case Float32:
+				return fmt.Sprintf(spec, a.data.([]float32)[i])
+			case Int64:
+				return fmt.Sprintf(spec, float64(a.data.([]int64)[i]))
+			default:
+				return strconv.FormatBool(a.data.([]bool)[i])
+			}
+		}))
+	default:
+		fmt.Fprintf(f, "%%!%c(*ndvek.NdArray)", verb)
 	}
+}
 
+// render writes the header line and the nested data, formatting each flat
+// offset with elem. Arrays larger than maxElems (when positive) are
+// summarized per axis. Elements of arrays with rank >= 2 are right-aligned
+// to a common width so columns line up.
+func (a *NdArray) render(maxElems int, elem func(i int) string) string {
 	var b strings.Builder
-	fmt.Fprintf(&b, "NdArray(shape=%v, dtype=%s, data=", a.shape, dtypeStr)
+	fmt.Fprintf(&b, "NdArray(shape=%v, dtype=%s)\n", a.shape, dtypeName(a.dtype))
 
-	size := ProdInt(a.shape)
-	const maxShow = 10
-	switch a.dtype {
-	case Float64:
-		d := a.data.([]float64)
-		b.WriteByte('[')
-		for i := range min(size, maxShow) {
-			if i > 0 {
-				b.WriteString(", ")
+	rank := len(a.shape)
+	if rank == 0 {
+		b.WriteString(elem(0))
+		return b.String()
+	}
+
+	edge := 0
+	if size := ProdInt(a.shape); maxElems > 0 && size > maxElems {
+		edge = min(printEdgeItems, max(1, maxElems/2))
+	}
+	// shown[ax] lists the indices printed along ax, with -1 marking "...".
+	shown := make([][]int, rank)
+	for ax, n := range a.shape {
+		if edge > 0 && n > 2*edge {
+			for i := range edge {
+				shown[ax] = append(shown[ax], i)
+			}
+			shown[ax] = append(shown[ax], -1)
+			for i := n - edge; i < n; i++ {
+				shown[ax] = append(shown[ax], i)
+			}
+		} else {
+			for i := range n {
+				shown[ax] = append(shown[ax], i)
 			}
-			fmt.Fprintf(&b, "%g", d[i])
-		}
-		if size > maxShow {
-			fmt.Fprintf(&b, ", ...(%d more)", size-maxShow)
 		}
-		b.WriteByte(']')
-	case Float32:
-		d := a.data.([]float32)
-		b.WriteByte('[')
-		for i := range min(size, maxShow) {
-			if i > 0 {
-				b.WriteString(", ")
+	}
+	strides := make([]int, rank)
+	stride := 1
+	for ax := rank - 1; ax >= 0; ax-- {
+		strides[ax] = stride
+		stride *= a.shape[ax]
+	}
+
+	// Format the visible elements once, in print order, to find the width.
+	var cells []string
+	var collect func(ax, offset int)
+	collect = func(ax, offset int) {
+		for _, i := range shown[ax] {
+			switch {
+			case i < 0:
+			case ax == rank-1:
+				cells = append(cells, elem(offset+i))
+			default:
+				collect(ax+1, offset+i*strides[ax])
 			}
-			fmt.Fprintf(&b, "%g", d[i])
 		}
-		if size > maxShow {
-			fmt.Fprintf(&b, ", ...(%d more)", size-maxShow)
+	}
+	collect(0, 0)
+	width := 0
+	if rank >= 2 {
+		for _, c := range cells {
+			width = max(width, len(c))
 		}
-		b.WriteByte(']')
-	case Bool:
-		d := a.data.([]bool)
+	}
+
+	next := 0
+	var write func(ax int, indent string)
+	write = func(ax int, indent string) {
 		b.WriteByte('[')
-		for i := range min(size, maxShow) {
-			if i > 0 {
-				b.WriteString(", ")
+		for k, i := range shown[ax] {
+			if k > 0 {
+				b.WriteByte(',')
+				if ax == rank-1 {
+					b.WriteByte(' ')
+				} else {
+					b.WriteString(strings.Repeat("\n", rank-ax-1))
+					b.WriteString(indent + " ")
+				}
+			}
+			switch {
+			case i < 0:
+				b.WriteString("...")
+			case ax == rank-1:
+				fmt.Fprintf(&b, "%*s", width, cells[next])
+				next++
+			default:
+				write(ax+1, indent+" ")
 			}
-			fmt.Fprintf(&b, "%t", d[i])
-		}
-		if size > maxShow {
-			fmt.Fprintf(&b, ", ...(%d more)", size-maxShow)
 		}
 		b.WriteByte(']')
 	}
-
-	b.WriteByte(')')
+	write(0, "")
 	return b.String()
 }
 
+// formatElem formats the element at flat offset i with the given precision.
+func (a *NdArray) formatElem(i, precision int) string {
+	format := byte('f')
+	if precision < 0 {
+		format = 'g'
+	}
+	switch a.dtype {
+	case Float64:
+		return strconv.FormatFloat(a.data.([]float64)[i], format, precision, 64)
+	case Float32:
+		return strconv.FormatFloat(float64(a.data.([]float32)[i]), format, precision, 32)
+	case Int64:
+		return strconv.FormatInt(a.data.([]int64)[i], 10)
+	default:
+		return strconv.FormatBool(a.data.([]bool)[i])
+	}
+}
+
+// dtypeName returns the lowercase name of a dtype, e.g. "float64".
+func dtypeName(d DType) string {
+	switch d {
+	case Float64:
+		return "float64"
+	case Float32:
+		return "float32"
+	case Bool:
+		return "bool"
+	case Int64:
+		return "int64"
+	default:
+		return fmt.Sprintf("DType(%d)", int(d))
+	}
+}
+
 func shapesEqual(a, b []int) bool {
 	if len(a) != len(b) {
 		return false
@@ -0,0 +1,834 @@
+package ndvek
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/viterin/vek"
+)
+
+// normalizeAxis resolves a possibly negative axis against the given rank.
+func normalizeAxis(axis, rank int) (int, error) {
+	if axis < -rank || axis >= rank {
+		return 0, fmt.Errorf("axis %d out of range for array of rank %d", axis, rank)
+	}
+	if axis < 0 {
+		axis += rank
+	}
+	return axis, nil
+}
+
+// normalizeAxes resolves possibly negative axes against rank and checks that
+// they are distinct.
+func normalizeAxes(axes []int, rank int) ([]int, error) {
+	out := make([]int, len(axes))
+	seen := make([]bool, rank)
+	for i, axis := range axes {
+		ax, err := normalizeAxis(axis, rank)
+		if err != nil {
+			return nil, err
+		}
+		if seen[ax] {
+			return nil, fmt.Errorf("duplicate axis %d in %v", ax, axes)
+		}
+		seen[ax] = true
+		out[i] = ax
+	}
+	return out, nil
+}
+
+// reduceAxes applies fn to each group of elements sharing the same index
+// outside of axes. The reduced axes are dropped from the result shape, or kept
+// with size 1 when keepDims is set. axes must already be normalized and distinct.
+// Elements within a group are passed to fn in row-major order.
+func (a *NdArray) reduceAxes(axes []int, keepDims bool, fn func([]float64) float64) (*NdArray, error) {
+	outShape, out, err := a.reduceAxesFloat64(axes, keepDims, fn)
+	if err != nil {
+		return nil, err
+	}
+	return fromFloat64(outShape, out, a.dtype), nil
+}
+
+// reduceAxesFloat64 is reduceAxes returning the raw float64 result, for
+// reductions whose output is not in the input's dtype.
+func (a *NdArray) reduceAxesFloat64(axes []int, keepDims bool, fn func([]float64) float64) ([]int, []float64, error) {
+	data, err := a.toFloat64()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reduced := make([]bool, len(a.shape))
+	for _, ax := range axes {
+		reduced[ax] = true
+	}
+
+	outShape := make([]int, 0, len(a.shape))
+	groupSize := 1
+	for i, dim := range a.shape {
+		switch {
+		case !reduced[i]:
+			outShape = append(outShape, dim)
+		case keepDims:
+			groupSize *= dim
+			outShape = append(outShape, 1)
+		default:
+			groupSize *= dim
+		}
+	}
+	numGroups := ProdInt(outShape)
+
+	// Scatter elements so each group is contiguous, then reduce each run.
+	groups := make([]float64, len(data))
+	filled := make([]int, numGroups)
+	for i, v := range data {
+		rem, outIdx, outStride := i, 0, 1
+		for ax := len(a.shape) - 1; ax >= 0; ax-- {
+			coord := rem % a.shape[ax]
+			rem /= a.shape[ax]
+			if !reduced[ax] {
+				outIdx += coord * outStride
+				outStride *= a.shape[ax]
+			}
+		}
+		groups[outIdx*groupSize+filled[outIdx]] = v
+		filled[outIdx]++
+	}
+
+	out := make([]float64, numGroups)
+	for g := range out {
+		out[g] = fn(groups[g*groupSize : (g+1)*groupSize])
+	}
+	return outShape, out, nil
+}
+
+// applyAlongAxis calls fn on each 1-D slice of a along axis (already
+// normalized) and returns a new float64 buffer holding the modified slices.
+func (a *NdArray) applyAlongAxis(axis int, fn func([]float64)) ([]float64, error) {
+	src, err := a.toFloat64()
+	if err != nil {
+		return nil, err
+	}
+	n := a.shape[axis]
+	inner := ProdInt(a.shape[axis+1:])
+	outer := ProdInt(a.shape[:axis])
+
+	out := make([]float64, len(src))
+	slice := make([]float64, n)
+	for o := range outer {
+		for i := range inner {
+			base := o*n*inner + i
+			for j := range n {
+				slice[j] = src[base+j*inner]
+			}
+			fn(slice)
+			for j := range n {
+				out[base+j*inner] = slice[j]
+			}
+		}
+	}
+	return out, nil
+}
+
+// takeAlongAxis returns the slices of a at the given positions along axis
+// (already normalized), in order, preserving the dtype.
+func (a *NdArray) takeAlongAxis(axis int, idx []int) *NdArray {
+	shape := slices.Clone(a.shape)
+	shape[axis] = len(idx)
+	switch a.dtype {
+	case Float32:
+		return &NdArray{shape: shape, data: takeAxis(a.data.([]float32), a.shape, axis, idx), dtype: Float32}
+	case Bool:
+		return &NdArray{shape: shape, data: takeAxis(a.data.([]bool), a.shape, axis, idx), dtype: Bool}
+	case Int64:
+		return &NdArray{shape: shape, data: takeAxis(a.data.([]int64), a.shape, axis, idx), dtype: Int64}
+	default:
+		return &NdArray{shape: shape, data: takeAxis(a.data.([]float64), a.shape, axis, idx), dtype: Float64}
+	}
+}
+
+func takeAxis[T any](src []T, shape []int, axis int, idx []int) []T {
+	n := shape[axis]
+	inner := ProdInt(shape[axis+1:])
+	outer := ProdInt(shape[:axis])
+	out := make([]T, 0, outer*len(idx)*inner)
+	for o := range outer {
+		for _, j := range idx {
+			base := (o*n + j) * inner
+			out = append(out, src[base:base+inner]...)
+		}
+	}
+	return out
+}
+
+// takeGrid returns the array whose element at (i0, i1, ...) is a's element at
+// (sel[0][i0], sel[1][i1], ...), preserving the dtype. sel holds one list of
+// source coordinates per axis.
+func (a *NdArray) takeGrid(sel [][]int) *NdArray {
+	shape := make([]int, len(sel))
+	for ax, s := range sel {
+		shape[ax] = len(s)
+	}
+	switch a.dtype {
+	case Float32:
+		return &NdArray{shape: shape, data: takeGridData(a.data.([]float32), a.shape, sel), dtype: Float32}
+	case Bool:
+		return &NdArray{shape: shape, data: takeGridData(a.data.([]bool), a.shape, sel), dtype: Bool}
+	case Int64:
+		return &NdArray{shape: shape, data: takeGridData(a.data.([]int64), a.shape, sel), dtype: Int64}
+	default:
+		return &NdArray{shape: shape, data: takeGridData(a.data.([]float64), a.shape, sel), dtype: Float64}
+	}
+}
+
+func takeGridData[T any](src []T, shape []int, sel [][]int) []T {
+	size := 1
+	for _, s := range sel {
+		size *= len(s)
+	}
+	out := make([]T, 0, size)
+	strides := make([]int, len(shape))
+	stride := 1
+	for ax := len(shape) - 1; ax >= 0; ax-- {
+		strides[ax] = stride
+		stride *= shape[ax]
+	}
+	var walk func(ax, offset int)
+	walk = func(ax, offset int) {
+		if ax == len(sel) {
+			out = append(out, src[offset])
+			return
+		}
+		for _, j := range sel[ax] {
+			walk(ax+1, offset+j*strides[ax])
+		}
+	}
+	if size > 0 {
+		walk(0, 0)
+	}
+	return out
+}
+
+// Tile repeats the whole array reps[i] times along each axis, like
+// numpy.tile. When reps is shorter than the rank it is padded with leading
+// ones; when longer, the array gains leading size-1 axes. A [2] array tiled
+// with reps [3] has length 6.
+func (a *NdArray) Tile(reps []int) (*NdArray, error) {
+	for _, r := range reps {
+		if r < 0 {
+			return nil, fmt.Errorf("tile repetitions must be non-negative, got %v", reps)
+		}
+	}
+	rank := max(len(a.shape), len(reps))
+	src := &NdArray{shape: make([]int, rank), data: a.data, dtype: a.dtype}
+	for i := range src.shape {
+		src.shape[i] = 1
+	}
+	copy(src.shape[rank-len(a.shape):], a.shape)
+
+	sel := make([][]int, rank)
+	for ax, n := range src.shape {
+		r := 1
+		if k := ax - (rank - len(reps)); k >= 0 {
+			r = reps[k]
+		}
+		sel[ax] = make([]int, n*r)
+		for j := range sel[ax] {
+			sel[ax][j] = j % n
+		}
+	}
+	return src.takeGrid(sel), nil
+}
+
+// Repeat repeats each element repeats times along axis, so [1, 2] repeated
+// twice on axis 0 becomes [1, 1, 2, 2]. Negative axes count from the end.
+func (a *NdArray) Repeat(repeats int, axis int) (*NdArray, error) {
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]int, a.shape[ax])
+	for i := range counts {
+		counts[i] = repeats
+	}
+	return a.RepeatEach(counts, ax)
+}
+
+// RepeatEach is Repeat with a separate count for each position along axis;
+// len(repeats) must equal that axis's length.
+func (a *NdArray) RepeatEach(repeats []int, axis int) (*NdArray, error) {
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, err
+	}
+	if len(repeats) != a.shape[ax] {
+		return nil, fmt.Errorf("got %d repeat counts for axis %d of length %d", len(repeats), ax, a.shape[ax])
+	}
+	sel := make([][]int, len(a.shape))
+	for i, n := range a.shape {
+		if i == ax {
+			continue
+		}
+		sel[i] = make([]int, n)
+		for j := range n {
+			sel[i][j] = j
+		}
+	}
+	for i, r := range repeats {
+		if r < 0 {
+			return nil, fmt.Errorf("repeat counts must be non-negative, got %d", r)
+		}
+		for range r {
+			sel[ax] = append(sel[ax], i)
+		}
+	}
+	return a.takeGrid(sel), nil
+}
+
+// ScaleAxis multiplies each slice of a along axis by the matching element of
+// the 1-D array scale, whose length must equal that axis, e.g. a per-channel
+// gain. Negative axes count from the end.
+func (a *NdArray) ScaleAxis(scale *NdArray, axis int) (*NdArray, error) {
+	s, err := a.alongAxis(scale, axis)
+	if err != nil {
+		return nil, err
+	}
+	return Multiply(a, s)
+}
+
+// ShiftAxis adds the matching element of the 1-D array shift to each slice of
+// a along axis, the additive companion to ScaleAxis.
+func (a *NdArray) ShiftAxis(shift *NdArray, axis int) (*NdArray, error) {
+	s, err := a.alongAxis(shift, axis)
+	if err != nil {
+		return nil, err
+	}
+	return Add(a, s)
+}
+
+// alongAxis returns a view of the 1-D array v shaped to broadcast against a
+// along axis.
+func (a *NdArray) alongAxis(v *NdArray, axis int) (*NdArray, error) {
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, err
+	}
+	if len(v.shape) != 1 || v.shape[0] != a.shape[ax] {
+		return nil, fmt.Errorf("expected a 1-D array of length %d for axis %d, got shape %v", a.shape[ax], ax, v.shape)
+	}
+	shape := make([]int, len(a.shape))
+	for i := range shape {
+		shape[i] = 1
+	}
+	shape[ax] = v.shape[0]
+	return &NdArray{shape: shape, data: v.data, dtype: v.dtype}, nil
+}
+
+// SumAxes sums over several axes at once, dropping them from the result shape.
+// Negative axes count from the end.
+func (a *NdArray) SumAxes(axes []int) (*NdArray, error) {
+	return a.reduceOverAxes(axes, vek.Sum)
+}
+
+// MeanAxes averages over several axes at once, dropping them from the result shape.
+func (a *NdArray) MeanAxes(axes []int) (*NdArray, error) {
+	return a.reduceOverAxes(axes, meanOrNaN)
+}
+
+// MaxAxes takes the maximum over several axes at once, dropping them from the result shape.
+func (a *NdArray) MaxAxes(axes []int) (*NdArray, error) {
+	return a.reduceOverAxes(axes, maxOrNaN)
+}
+
+func (a *NdArray) reduceOverAxes(axes []int, fn func([]float64) float64) (*NdArray, error) {
+	norm, err := normalizeAxes(axes, len(a.shape))
+	if err != nil {
+		return nil, err
+	}
+	return a.reduceAxes(norm, false, fn)
+}
+
+// SumAxis sums along a single axis. The axis is dropped from the result shape,
+// or kept with size 1 when keepDims is set. Negative axes count from the end.
+func (a *NdArray) SumAxis(axis int, keepDims bool) (*NdArray, error) {
+	return a.reduceOverAxis(axis, keepDims, vek.Sum)
+}
+
+// MeanAxis averages along a single axis. Float32 input stays Float32; Int64
+// is widened to Float64.
+func (a *NdArray) MeanAxis(axis int, keepDims bool) (*NdArray, error) {
+	return a.reduceOverAxis(axis, keepDims, meanOrNaN)
+}
+
+// MinAxis takes the minimum along a single axis. Float32 input stays Float32;
+// Int64 is widened to Float64.
+func (a *NdArray) MinAxis(axis int, keepDims bool) (*NdArray, error) {
+	return a.reduceOverAxis(axis, keepDims, minOrNaN)
+}
+
+// MaxAxis takes the maximum along a single axis. Float32 input stays Float32;
+// Int64 is widened to Float64.
+func (a *NdArray) MaxAxis(axis int, keepDims bool) (*NdArray, error) {
+	return a.reduceOverAxis(axis, keepDims, maxOrNaN)
+}
+
+// VarAxis computes the variance along a single axis with ddof delta degrees
+// of freedom (see Var). Float32 input stays Float32; Int64 gives Float64.
+func (a *NdArray) VarAxis(axis int, keepDims bool, ddof int) (*NdArray, error) {
+	return a.reduceOverAxis(axis, keepDims, func(x []float64) float64 {
+		return varianceDdof(x, ddof)
+	})
+}
+
+// StdAxis computes the standard deviation along a single axis with ddof delta
+// degrees of freedom. Float32 input stays Float32; Int64 gives Float64.
+func (a *NdArray) StdAxis(axis int, keepDims bool, ddof int) (*NdArray, error) {
+	return a.reduceOverAxis(axis, keepDims, func(x []float64) float64 {
+		return math.Sqrt(varianceDdof(x, ddof))
+	})
+}
+
+// Median computes the median along a single axis. Even-length slices average
+// their two middle values. Float32 input stays Float32; Int64 gives Float64.
+func (a *NdArray) Median(axis int, keepDims bool) (*NdArray, error) {
+	return a.Percentile(50, axis, keepDims)
+}
+
+// Percentile computes the q-th percentile (0 <= q <= 100) along a single
+// axis, interpolating linearly between the closest data points as NumPy does
+// by default. Float32 input stays Float32; Int64 is widened to Float64.
+func (a *NdArray) Percentile(q float64, axis int, keepDims bool) (*NdArray, error) {
+	if !(q >= 0 && q <= 100) {
+		return nil, fmt.Errorf("percentile must be in [0, 100], got %g", q)
+	}
+	return a.reduceOverAxis(axis, keepDims, func(x []float64) float64 {
+		if len(x) == 0 {
+			return math.NaN()
+		}
+		return vek.Quantile(x, q/100)
+	})
+}
+
+func (a *NdArray) reduceOverAxis(axis int, keepDims bool, fn func([]float64) float64) (*NdArray, error) {
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, err
+	}
+	return a.reduceAxes([]int{ax}, keepDims, fn)
+}
+
+// meanOrNaN, minOrNaN and maxOrNaN reduce empty slices to NaN rather than
+// panicking inside vek.
+func meanOrNaN(x []float64) float64 {
+	if len(x) == 0 {
+		return math.NaN()
+	}
+	return vek.Mean(x)
+}
+
+func minOrNaN(x []float64) float64 {
+	if len(x) == 0 {
+		return math.NaN()
+	}
+	return vek.Min(x)
+}
+
+func maxOrNaN(x []float64) float64 {
+	if len(x) == 0 {
+		return math.NaN()
+	}
+	return vek.Max(x)
+}
+
+// NanSum returns the sum of all elements, ignoring NaN values.
+func (a *NdArray) NanSum() float64 {
+	sum, _ := nanSumCount(a.mustFloat64())
+	return sum
+}
+
+// NanMean returns the mean of all elements, ignoring NaN values.
+// Returns NaN when every element is NaN.
+func (a *NdArray) NanMean() float64 {
+	sum, n := nanSumCount(a.mustFloat64())
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum / float64(n)
+}
+
+// NanSumMinCount sums along axis ignoring NaN values. Slices with fewer than
+// minCount non-NaN elements reduce to NaN, matching pandas' min_count.
+func (a *NdArray) NanSumMinCount(axis, minCount int) (*NdArray, error) {
+	return a.nanReduceMinCount(axis, minCount, func(sum float64, n int) float64 { return sum })
+}
+
+// NanMeanMinCount averages along axis ignoring NaN values. Slices with fewer
+// than minCount non-NaN elements reduce to NaN; an all-NaN slice is always NaN.
+func (a *NdArray) NanMeanMinCount(axis, minCount int) (*NdArray, error) {
+	return a.nanReduceMinCount(axis, max(minCount, 1), func(sum float64, n int) float64 {
+		return sum / float64(n)
+	})
+}
+
+func (a *NdArray) nanReduceMinCount(axis, minCount int, finish func(sum float64, n int) float64) (*NdArray, error) {
+	if minCount < 0 {
+		return nil, fmt.Errorf("minCount must be non-negative, got %d", minCount)
+	}
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, err
+	}
+	return a.reduceAxes([]int{ax}, false, func(vals []float64) float64 {
+		sum, n := nanSumCount(vals)
+		if n < minCount {
+			return math.NaN()
+		}
+		return finish(sum, n)
+	})
+}
+
+// NanArgMax returns the index of the largest non-NaN element along axis as a
+// Float64 array. Returns an error if any slice is entirely NaN.
+func (a *NdArray) NanArgMax(axis int) (*NdArray, error) {
+	return a.nanArg(axis, func(v, best float64) bool { return v > best })
+}
+
+// NanArgMin returns the index of the smallest non-NaN element along axis as a
+// Float64 array. Returns an error if any slice is entirely NaN.
+func (a *NdArray) NanArgMin(axis int) (*NdArray, error) {
+	return a.nanArg(axis, func(v, best float64) bool { return v < best })
+}
+
+func (a *NdArray) nanArg(axis int, better func(v, best float64) bool) (*NdArray, error) {
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, err
+	}
+	allNaN := false
+	shape, out, err := a.reduceAxesFloat64([]int{ax}, false, func(vals []float64) float64 {
+		idx := -1
+		for i, v := range vals {
+			if !math.IsNaN(v) && (idx < 0 || better(v, vals[idx])) {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			allNaN = true
+		}
+		return float64(idx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if allNaN {
+		return nil, errors.New("all-NaN slice encountered")
+	}
+	return &NdArray{shape: shape, data: out, dtype: Float64}, nil
+}
+
+// nanSumCount returns the sum and count of the non-NaN values in x.
+func nanSumCount(x []float64) (float64, int) {
+	sum, n := 0.0, 0
+	for _, v := range x {
+		if !math.IsNaN(v) {
+			sum += v
+			n++
+		}
+	}
+	return sum, n
+}
+
+// ClampProbs clips each element to [eps, 1-eps] and renormalizes every slice
+// along axis to sum to one. Negative entries are rejected. A slice that is
+// entirely zero (possible only when eps is 0) becomes uniform.
+func (a *NdArray) ClampProbs(axis int, eps float64) (*NdArray, error) {
+	if !(eps >= 0 && eps < 0.5) {
+		return nil, fmt.Errorf("eps must be in [0, 0.5), got %g", eps)
+	}
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, err
+	}
+
+	var negative bool
+	out, err := a.applyAlongAxis(ax, func(p []float64) {
+		sum := 0.0
+		for j, v := range p {
+			if v < 0 {
+				negative = true
+			}
+			p[j] = min(max(v, eps), 1-eps)
+			sum += p[j]
+		}
+		for j := range p {
+			if sum > 0 {
+				p[j] /= sum
+			} else {
+				p[j] = 1 / float64(len(p))
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if negative {
+		return nil, errors.New("ClampProbs requires non-negative values")
+	}
+	return fromFloat64(a.shape, out, a.dtype), nil
+}
+
+// LayerNorm normalizes each slice of a along axis to zero mean and unit
+// variance, computing (x - mean) / sqrt(var + eps) with the population
+// variance. The dtype is preserved for float arrays.
+func (a *NdArray) LayerNorm(axis int, eps float64) (*NdArray, error) {
+	out, _, _, err := a.LayerNormStats(axis, eps)
+	return out, err
+}
+
+// LayerNormStats is LayerNorm that also returns the per-slice mean and
+// variance, shaped like a with axis reduced to size 1 so they broadcast back
+// against it, as needed for a backward pass.
+func (a *NdArray) LayerNormStats(axis int, eps float64) (out, mean, variance *NdArray, err error) {
+	if !(eps > 0) {
+		return nil, nil, nil, fmt.Errorf("eps must be positive, got %g", eps)
+	}
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var means, vars []float64
+	data, err := a.applyAlongAxis(ax, func(x []float64) {
+		mu := vek.Mean(x)
+		v := varianceDdof(x, 0)
+		scale := 1 / math.Sqrt(v+eps)
+		for j := range x {
+			x[j] = (x[j] - mu) * scale
+		}
+		means = append(means, mu)
+		vars = append(vars, v)
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	statShape := slices.Clone(a.shape)
+	statShape[ax] = 1
+	return fromFloat64(slices.Clone(a.shape), data, a.dtype),
+		fromFloat64(statShape, means, a.dtype),
+		fromFloat64(slices.Clone(statShape), vars, a.dtype), nil
+}
+
+// MaskedSoftmax computes a numerically stable softmax of logits along axis
+// after setting positions where the Bool mask is false to -Inf, so they get
+// zero probability. The mask broadcasts against logits. A slice with every
+// position masked yields zeros rather than NaN. Float32 logits stay Float32;
+// other numeric dtypes produce Float64.
+func MaskedSoftmax(logits, mask *NdArray, axis int) (*NdArray, error) {
+	if mask.dtype != Bool {
+		return nil, fmt.Errorf("MaskedSoftmax mask must be a Bool array, got %s", dtypeName(mask.dtype))
+	}
+	if logits.dtype == Bool {
+		return nil, errors.New("MaskedSoftmax not supported for Bool logits")
+	}
+	li, mi, shape, err := BroadcastIndices(logits.shape, mask.shape)
+	if err != nil {
+		return nil, err
+	}
+	ax, err := normalizeAxis(axis, len(shape))
+	if err != nil {
+		return nil, err
+	}
+	src := logits.mustFloat64()
+	keep := mask.data.([]bool)
+	data := make([]float64, len(li))
+	for i := range data {
+		if keep[mi[i]] {
+			data[i] = src[li[i]]
+		} else {
+			data[i] = math.Inf(-1)
+		}
+	}
+	masked := &NdArray{shape: shape, data: data, dtype: Float64}
+
+	out, err := masked.applyAlongAxis(ax, func(x []float64) {
+		m := math.Inf(-1)
+		for _, v := range x {
+			m = max(m, v)
+		}
+		if math.IsInf(m, -1) {
+			clear(x)
+			return
+		}
+		sum := 0.0
+		for j, v := range x {
+			x[j] = math.Exp(v - m)
+			sum += x[j]
+		}
+		vek.DivNumber_Inplace(x, sum)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromFloat64(shape, out, logits.dtype), nil
+}
+
+// CumCount returns, for a Bool array, the running number of true values along
+// axis (inclusive of the current position) as a Float64 array of the same shape.
+func (a *NdArray) CumCount(axis int) (*NdArray, error) {
+	if a.dtype != Bool {
+		return nil, errors.New("CumCount requires a Bool array")
+	}
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, err
+	}
+	ones := &NdArray{shape: a.shape, data: vek.FromBool(a.data.([]bool)), dtype: Float64}
+	out, err := ones.applyAlongAxis(ax, vek.CumSum_Inplace)
+	if err != nil {
+		return nil, err
+	}
+	return &NdArray{shape: a.Shape(), data: out, dtype: Float64}, nil
+}
+
+// IsSorted reports whether every slice along axis is non-decreasing, or
+// strictly increasing when strict is set. An axis of -1 checks the flattened
+// array rather than the last axis. NaN values are never considered sorted.
+func (a *NdArray) IsSorted(axis int, strict bool) (bool, error) {
+	inOrder := func(prev, cur float64) bool { return cur >= prev }
+	if strict {
+		inOrder = func(prev, cur float64) bool { return cur > prev }
+	}
+	check := func(vals []float64) bool {
+		for i, v := range vals {
+			if math.IsNaN(v) || (i > 0 && !inOrder(vals[i-1], v)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if axis == -1 {
+		data, err := a.toFloat64()
+		if err != nil {
+			return false, err
+		}
+		return check(data), nil
+	}
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return false, err
+	}
+	sorted := true
+	_, err = a.applyAlongAxis(ax, func(vals []float64) {
+		sorted = sorted && check(vals)
+	})
+	return sorted, err
+}
+
+// Argsort1D returns the permutation of indices that sorts the 1-D array a,
+// in ascending order or descending when requested. Ties keep their original
+// order, NaN values are placed last in either direction, and Bool arrays
+// order false before true. Int64 values are compared exactly.
+func Argsort1D(a *NdArray, descending bool) ([]int, error) {
+	if len(a.shape) != 1 {
+		return nil, fmt.Errorf("Argsort1D requires a 1-D array, got shape %v", a.shape)
+	}
+	idx := make([]int, a.shape[0])
+	for i := range idx {
+		idx[i] = i
+	}
+	sign := 1
+	if descending {
+		sign = -1
+	}
+
+	switch a.dtype {
+	case Int64:
+		d := a.data.([]int64)
+		slices.SortStableFunc(idx, func(i, j int) int { return sign * cmp.Compare(d[i], d[j]) })
+	case Bool:
+		d := a.data.([]bool)
+		slices.SortStableFunc(idx, func(i, j int) int { return sign * compareBool(d[i], d[j]) })
+	default:
+		d := a.mustFloat64()
+		order := nanLastOrder[float64](sign)
+		slices.SortStableFunc(idx, func(i, j int) int { return order(d[i], d[j]) })
+	}
+	return idx, nil
+}
+
+// Sort returns a copy of a with every slice along axis sorted, ascending or
+// descending. Ties keep their original order and NaN values are placed at the
+// end of each slice in either direction. The dtype is preserved, and Bool
+// arrays order false before true. Negative axes count from the end.
+func (a *NdArray) Sort(axis int, descending bool) (*NdArray, error) {
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, err
+	}
+	sign := 1
+	if descending {
+		sign = -1
+	}
+	shape := slices.Clone(a.shape)
+	switch a.dtype {
+	case Float32:
+		return &NdArray{shape: shape, data: sortAlongAxis(a.data.([]float32), a.shape, ax, nanLastOrder[float32](sign)), dtype: Float32}, nil
+	case Bool:
+		order := func(x, y bool) int { return sign * compareBool(x, y) }
+		return &NdArray{shape: shape, data: sortAlongAxis(a.data.([]bool), a.shape, ax, order), dtype: Bool}, nil
+	case Int64:
+		order := func(x, y int64) int { return sign * cmp.Compare(x, y) }
+		return &NdArray{shape: shape, data: sortAlongAxis(a.data.([]int64), a.shape, ax, order), dtype: Int64}, nil
+	default:
+		return &NdArray{shape: shape, data: sortAlongAxis(a.data.([]float64), a.shape, ax, nanLastOrder[float64](sign)), dtype: Float64}, nil
+	}
+}
+
+// sortAlongAxis returns a copy of src with each slice along axis stably
+// sorted by order.
+func sortAlongAxis[T any](src []T, shape []int, axis int, order func(T, T) int) []T {
+	out := slices.Clone(src)
+	n := shape[axis]
+	inner := ProdInt(shape[axis+1:])
+	outer := ProdInt(shape[:axis])
+	slice := make([]T, n)
+	for o := range outer {
+		for i := range inner {
+			base := o*n*inner + i
+			for j := range n {
+				slice[j] = out[base+j*inner]
+			}
+			slices.SortStableFunc(slice, order)
+			for j := range n {
+				out[base+j*inner] = slice[j]
+			}
+		}
+	}
+	return out
+}
+
+// nanLastOrder compares floats ascending (sign 1) or descending (sign -1),
+// always ordering NaN after every other value.
+func nanLastOrder[T float32 | float64](sign int) func(x, y T) int {
+	return func(x, y T) int {
+		if xNaN, yNaN := x != x, y != y; xNaN || yNaN {
+			return compareBool(xNaN, yNaN)
+		}
+		return sign * cmp.Compare(x, y)
+	}
+}
+
+// compareBool orders false before true.
+func compareBool(x, y bool) int {
+	switch {
+	case x == y:
+		return 0
+	case y:
+		return -1
+	default:
+		return 1
+	}
+}
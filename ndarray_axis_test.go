@@ -0,0 +1,581 @@
+package ndvek
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNanReductions(t *testing.T) {
+	nan := math.NaN()
+	a, _ := NewNdArray([]int{2, 3}, []float64{1, nan, 3, nan, nan, 6})
+
+	if got := a.NanSum(); got != 10 {
+		t.Errorf("NanSum: expected 10, got %v", got)
+	}
+	if got := a.NanMean(); got != 10.0/3.0 {
+		t.Errorf("NanMean: expected %v, got %v", 10.0/3.0, got)
+	}
+
+	sum, err := a.NanSumMinCount(1, 2)
+	if err != nil {
+		t.Fatalf("NanSumMinCount: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(sum.Shape(), []int{2}) {
+		t.Errorf("NanSumMinCount: expected shape [2], got %v", sum.Shape())
+	}
+	got := sum.Float64Data()
+	if got[0] != 4 || !math.IsNaN(got[1]) {
+		t.Errorf("NanSumMinCount: expected [4 NaN], got %v", got)
+	}
+
+	mean, err := a.NanMeanMinCount(-2, 0)
+	if err != nil {
+		t.Fatalf("NanMeanMinCount: unexpected error: %v", err)
+	}
+	got = mean.Float64Data()
+	if got[0] != 1 || !math.IsNaN(got[1]) || got[2] != 4.5 {
+		t.Errorf("NanMeanMinCount: expected [1 NaN 4.5], got %v", got)
+	}
+
+	if _, err := a.NanSumMinCount(0, -1); err == nil {
+		t.Error("NanSumMinCount: expected error for negative minCount")
+	}
+	if _, err := a.NanSumMinCount(2, 0); err == nil {
+		t.Error("NanSumMinCount: expected error for out-of-range axis")
+	}
+
+	b, _ := NewNdArray([]int{2, 2}, []float32{1, 2, 3, float32(nan)})
+	res, _ := b.NanSumMinCount(0, 1)
+	if res.DType() != Float32 || !reflect.DeepEqual(res.Float32Data(), []float32{4, 2}) {
+		t.Errorf("NanSumMinCount float32: expected Float32 [4 2], got %v", res)
+	}
+}
+
+func TestNanArgMaxMin(t *testing.T) {
+	nan := math.NaN()
+	a, _ := NewNdArray([]int{2, 3}, []float64{nan, 5, 2, 7, nan, 1})
+
+	res, err := a.NanArgMax(1)
+	if err != nil {
+		t.Fatalf("NanArgMax: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(res.Float64Data(), []float64{1, 0}) {
+		t.Errorf("NanArgMax: expected [1 0], got %v", res.Float64Data())
+	}
+
+	res, err = a.NanArgMin(-1)
+	if err != nil {
+		t.Fatalf("NanArgMin: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(res.Float64Data(), []float64{2, 2}) {
+		t.Errorf("NanArgMin: expected [2 2], got %v", res.Float64Data())
+	}
+
+	res, _ = a.NanArgMax(0)
+	if !reflect.DeepEqual(res.Float64Data(), []float64{1, 0, 0}) {
+		t.Errorf("NanArgMax axis 0: expected [1 0 0], got %v", res.Float64Data())
+	}
+
+	b, _ := NewNdArray([]int{2, 2}, []float32{float32(nan), 1, float32(nan), 2})
+	if _, err := b.NanArgMax(0); err == nil {
+		t.Error("NanArgMax: expected error for all-NaN slice")
+	}
+	res, _ = b.NanArgMin(1)
+	if res.DType() != Float64 || !reflect.DeepEqual(res.Float64Data(), []float64{1, 1}) {
+		t.Errorf("NanArgMin float32: expected Float64 [1 1], got %v", res)
+	}
+}
+
+func TestClampProbs(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float64{0, 1, 0.5, 0.5})
+
+	res, err := a.ClampProbs(1, 0.1)
+	if err != nil {
+		t.Fatalf("ClampProbs: unexpected error: %v", err)
+	}
+	expected := []float64{0.1, 0.9, 0.5, 0.5}
+	got := res.Float64Data()
+	for i := range expected {
+		if math.Abs(got[i]-expected[i]) > 1e-12 {
+			t.Fatalf("ClampProbs: expected %v, got %v", expected, got)
+		}
+	}
+
+	// Renormalization along axis 0
+	b, _ := NewNdArray([]int{2, 2}, []float64{0.25, 0.75, 0.25, 0.25})
+	res, _ = b.ClampProbs(0, 0)
+	expected = []float64{0.5, 0.75, 0.5, 0.25}
+	if !reflect.DeepEqual(res.Float64Data(), expected) {
+		t.Errorf("ClampProbs axis 0: expected %v, got %v", expected, res.Float64Data())
+	}
+
+	// All-zero slice with eps == 0 becomes uniform
+	c, _ := NewNdArray([]int{4}, []float32{0, 0, 0, 0})
+	res, _ = c.ClampProbs(0, 0)
+	if res.DType() != Float32 || !reflect.DeepEqual(res.Float32Data(), []float32{0.25, 0.25, 0.25, 0.25}) {
+		t.Errorf("ClampProbs zero slice: expected Float32 uniform, got %v", res)
+	}
+
+	neg, _ := NewNdArray([]int{2}, []float64{-0.5, 1})
+	if _, err := neg.ClampProbs(0, 0.01); err == nil {
+		t.Error("ClampProbs: expected error for negative values")
+	}
+	if _, err := a.ClampProbs(0, 0.6); err == nil {
+		t.Error("ClampProbs: expected error for eps >= 0.5")
+	}
+	if _, err := a.ClampProbs(3, 0.1); err == nil {
+		t.Error("ClampProbs: expected error for invalid axis")
+	}
+}
+
+func TestCumCount(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []bool{true, false, true, false, true, true})
+
+	rows, err := a.CumCount(1)
+	if err != nil {
+		t.Fatalf("CumCount: unexpected error: %v", err)
+	}
+	if expected := []float64{1, 1, 2, 0, 1, 2}; !reflect.DeepEqual(rows.Float64Data(), expected) {
+		t.Errorf("CumCount(1): expected %v, got %v", expected, rows.Float64Data())
+	}
+
+	cols, _ := a.CumCount(-2)
+	if expected := []float64{1, 0, 1, 1, 1, 2}; !reflect.DeepEqual(cols.Float64Data(), expected) {
+		t.Errorf("CumCount(-2): expected %v, got %v", expected, cols.Float64Data())
+	}
+
+	if _, err := a.CumCount(2); err == nil {
+		t.Error("CumCount: expected error for out-of-range axis")
+	}
+	f, _ := NewNdArray([]int{2}, []float64{1, 0})
+	if _, err := f.CumCount(0); err == nil {
+		t.Error("CumCount: expected error for a non-Bool array")
+	}
+}
+
+func TestIsSorted(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 2, 0, 5, 6})
+
+	tests := []struct {
+		axis     int
+		strict   bool
+		expected bool
+	}{
+		{-1, false, false},
+		{1, false, true},
+		{1, true, false},
+		{0, false, false},
+	}
+	for _, tt := range tests {
+		got, err := a.IsSorted(tt.axis, tt.strict)
+		if err != nil {
+			t.Fatalf("IsSorted(%d, %v): unexpected error: %v", tt.axis, tt.strict, err)
+		}
+		if got != tt.expected {
+			t.Errorf("IsSorted(%d, %v): expected %v, got %v", tt.axis, tt.strict, tt.expected, got)
+		}
+	}
+
+	b, _ := NewNdArray([]int{4}, []float32{-1, 0, 2.5, 3})
+	if ok, _ := b.IsSorted(-1, true); !ok {
+		t.Error("IsSorted: expected strictly increasing float32 array to be sorted")
+	}
+	c, _ := NewNdArray([]int{3}, []float64{1, math.NaN(), 3})
+	if ok, _ := c.IsSorted(-1, false); ok {
+		t.Error("IsSorted: expected array containing NaN to be unsorted")
+	}
+	if _, err := a.IsSorted(2, false); err == nil {
+		t.Error("IsSorted: expected error for invalid axis")
+	}
+}
+
+func TestSingleAxisReductions(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []float64{1, 5, 3, 4, 2, 6})
+
+	mean, err := a.MeanAxis(1, false)
+	if err != nil {
+		t.Fatalf("MeanAxis: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(mean.Shape(), []int{2}) || !reflect.DeepEqual(mean.Float64Data(), []float64{3, 4}) {
+		t.Errorf("MeanAxis(1): expected [3 4], got %v", mean)
+	}
+
+	minRes, _ := a.MinAxis(0, true)
+	if !reflect.DeepEqual(minRes.Shape(), []int{1, 3}) || !reflect.DeepEqual(minRes.Float64Data(), []float64{1, 2, 3}) {
+		t.Errorf("MinAxis(0, keepDims): expected [[1 2 3]], got %v", minRes)
+	}
+
+	maxRes, _ := a.MaxAxis(-1, true)
+	if !reflect.DeepEqual(maxRes.Shape(), []int{2, 1}) || !reflect.DeepEqual(maxRes.Float64Data(), []float64{5, 6}) {
+		t.Errorf("MaxAxis(-1, keepDims): expected [[5] [6]], got %v", maxRes)
+	}
+
+	sum, _ := a.SumAxis(0, false)
+	if !reflect.DeepEqual(sum.Float64Data(), []float64{5, 7, 9}) {
+		t.Errorf("SumAxis(0): expected [5 7 9], got %v", sum)
+	}
+
+	b, _ := NewNdArray([]int{2, 2}, []float32{1, 2, 3, 4})
+	res, _ := b.MeanAxis(0, false)
+	if res.DType() != Float32 || !reflect.DeepEqual(res.Float32Data(), []float32{2, 3}) {
+		t.Errorf("MeanAxis float32: expected Float32 [2 3], got %v", res)
+	}
+
+	if _, err := a.MaxAxis(2, false); err == nil {
+		t.Error("MaxAxis: expected error for out-of-range axis")
+	}
+	if _, err := a.MinAxis(-3, false); err == nil {
+		t.Error("MinAxis: expected error for out-of-range negative axis")
+	}
+}
+
+func TestVarStdAxis(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 4}, []float64{2, 4, 4, 4, 5, 5, 7, 9})
+
+	pop, err := a.VarAxis(1, false, 0)
+	if err != nil {
+		t.Fatalf("VarAxis: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(pop.Float64Data(), []float64{0.75, 2.75}) {
+		t.Errorf("VarAxis(ddof=0): expected [0.75 2.75], got %v", pop.Float64Data())
+	}
+	sample, _ := a.VarAxis(-1, true, 1)
+	if !reflect.DeepEqual(sample.Shape(), []int{2, 1}) || !reflect.DeepEqual(sample.Float64Data(), []float64{1, 11.0 / 3}) {
+		t.Errorf("VarAxis(ddof=1, keepDims): expected [[1] [3.667]], got %v %v", sample.Shape(), sample.Float64Data())
+	}
+
+	std, _ := a.StdAxis(0, false, 0)
+	expected := []float64{1.5, 0.5, 1.5, 2.5}
+	if !reflect.DeepEqual(std.Float64Data(), expected) {
+		t.Errorf("StdAxis(0): expected %v, got %v", expected, std.Float64Data())
+	}
+
+	short, _ := a.VarAxis(0, false, 2)
+	if !math.IsNaN(short.Float64Data()[0]) {
+		t.Errorf("VarAxis: expected NaN when ddof >= n, got %v", short.Float64Data())
+	}
+	if _, err := a.StdAxis(2, false, 0); err == nil {
+		t.Error("StdAxis: expected error for out-of-range axis")
+	}
+}
+
+func TestMedianPercentile(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 4}, []float64{4, 1, 3, 2, 10, 30, 20, 40})
+
+	med, err := a.Median(1, false)
+	if err != nil {
+		t.Fatalf("Median: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(med.Float64Data(), []float64{2.5, 25}) {
+		t.Errorf("Median(1): expected [2.5 25], got %v", med.Float64Data())
+	}
+
+	p, _ := a.Percentile(25, -1, true)
+	if !reflect.DeepEqual(p.Shape(), []int{2, 1}) || !reflect.DeepEqual(p.Float64Data(), []float64{1.75, 17.5}) {
+		t.Errorf("Percentile(25, keepDims): expected [[1.75] [17.5]], got %v %v", p.Shape(), p.Float64Data())
+	}
+	hi, _ := a.Percentile(100, 0, false)
+	if !reflect.DeepEqual(hi.Float64Data(), []float64{10, 30, 20, 40}) {
+		t.Errorf("Percentile(100, 0): expected column maxima, got %v", hi.Float64Data())
+	}
+
+	b, _ := NewNdArray([]int{3}, []float32{5, 1, 3})
+	if m, _ := b.Median(0, false); m.DType() != Float32 || m.Float32Data()[0] != 3 {
+		t.Errorf("Median float32: expected Float32 [3], got %v", m)
+	}
+
+	if _, err := a.Percentile(101, 0, false); err == nil {
+		t.Error("Percentile: expected error for q > 100")
+	}
+	if _, err := a.Median(2, false); err == nil {
+		t.Error("Median: expected error for out-of-range axis")
+	}
+}
+
+func TestMultiAxisReductions(t *testing.T) {
+	// Shape [2, 2, 3]: values 0..11
+	data := make([]float64, 12)
+	for i := range data {
+		data[i] = float64(i)
+	}
+	a, _ := NewNdArray([]int{2, 2, 3}, data)
+
+	sum, err := a.SumAxes([]int{1, 2})
+	if err != nil {
+		t.Fatalf("SumAxes: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(sum.Shape(), []int{2}) || !reflect.DeepEqual(sum.Float64Data(), []float64{15, 51}) {
+		t.Errorf("SumAxes([1 2]): expected [15 51], got %v", sum)
+	}
+
+	mean, _ := a.MeanAxes([]int{0, -1})
+	if !reflect.DeepEqual(mean.Shape(), []int{2}) || !reflect.DeepEqual(mean.Float64Data(), []float64{4, 7}) {
+		t.Errorf("MeanAxes([0 -1]): expected [4 7], got %v", mean)
+	}
+
+	maxRes, _ := a.MaxAxes([]int{0})
+	expected := []float64{6, 7, 8, 9, 10, 11}
+	if !reflect.DeepEqual(maxRes.Shape(), []int{2, 3}) || !reflect.DeepEqual(maxRes.Float64Data(), expected) {
+		t.Errorf("MaxAxes([0]): expected %v, got %v", expected, maxRes)
+	}
+
+	all, _ := a.SumAxes([]int{0, 1, 2})
+	if len(all.Shape()) != 0 || all.Float64Data()[0] != 66 {
+		t.Errorf("SumAxes(all): expected rank-0 66, got %v", all)
+	}
+
+	if _, err := a.SumAxes([]int{1, -2}); err == nil {
+		t.Error("SumAxes: expected error for duplicate axes")
+	}
+	if _, err := a.MeanAxes([]int{3}); err == nil {
+		t.Error("MeanAxes: expected error for out-of-range axis")
+	}
+}
+
+func TestScaleShiftAxis(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 3, 4, 5, 6})
+	rows, _ := NewNdArray([]int{2}, []float64{10, 100})
+	cols, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+
+	scaled, err := a.ScaleAxis(rows, 0)
+	if err != nil {
+		t.Fatalf("ScaleAxis: unexpected error: %v", err)
+	}
+	expected := []float64{10, 20, 30, 400, 500, 600}
+	if !reflect.DeepEqual(scaled.Float64Data(), expected) {
+		t.Errorf("ScaleAxis: expected %v, got %v", expected, scaled.Float64Data())
+	}
+
+	shifted, err := a.ShiftAxis(cols, -1)
+	if err != nil {
+		t.Fatalf("ShiftAxis: unexpected error: %v", err)
+	}
+	expected = []float64{2, 4, 6, 5, 7, 9}
+	if !reflect.DeepEqual(shifted.Float64Data(), expected) || !reflect.DeepEqual(shifted.Shape(), []int{2, 3}) {
+		t.Errorf("ShiftAxis: expected [2 3] %v, got %v %v", expected, shifted.Shape(), shifted.Float64Data())
+	}
+
+	if _, err := a.ScaleAxis(cols, 0); err == nil {
+		t.Error("ScaleAxis: expected error for length mismatch")
+	}
+	if _, err := a.ShiftAxis(a, 1); err == nil {
+		t.Error("ShiftAxis: expected error for non 1-D shift")
+	}
+}
+
+func TestArgsort1D(t *testing.T) {
+	nan := math.NaN()
+	a, _ := NewNdArray([]int{6}, []float64{3, 1, nan, 2, 1, 3})
+
+	asc, err := Argsort1D(a, false)
+	if err != nil {
+		t.Fatalf("Argsort1D: unexpected error: %v", err)
+	}
+	if expected := []int{1, 4, 3, 0, 5, 2}; !reflect.DeepEqual(asc, expected) {
+		t.Errorf("Argsort1D ascending: expected %v, got %v", expected, asc)
+	}
+	desc, _ := Argsort1D(a, true)
+	if expected := []int{0, 5, 3, 1, 4, 2}; !reflect.DeepEqual(desc, expected) {
+		t.Errorf("Argsort1D descending: expected %v, got %v", expected, desc)
+	}
+
+	big, _ := NewNdArray([]int{3}, []int64{1<<60 + 1, 1 << 60, -5})
+	if idx, _ := Argsort1D(big, false); !reflect.DeepEqual(idx, []int{2, 1, 0}) {
+		t.Errorf("Argsort1D Int64: expected [2 1 0], got %v", idx)
+	}
+	flags, _ := NewNdArray([]int{4}, []bool{true, false, true, false})
+	if idx, _ := Argsort1D(flags, true); !reflect.DeepEqual(idx, []int{0, 2, 1, 3}) {
+		t.Errorf("Argsort1D Bool: expected [0 2 1 3], got %v", idx)
+	}
+
+	m, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+	if _, err := Argsort1D(m, false); err == nil {
+		t.Error("Argsort1D: expected error for 2-D input")
+	}
+}
+
+func TestLayerNorm(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 4}, []float64{1, 2, 3, 4, 10, 10, 10, 10})
+
+	out, mean, variance, err := a.LayerNormStats(-1, 1e-5)
+	if err != nil {
+		t.Fatalf("LayerNormStats: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(mean.Shape(), []int{2, 1}) || !reflect.DeepEqual(mean.Float64Data(), []float64{2.5, 10}) {
+		t.Errorf("LayerNormStats: expected mean [[2.5] [10]], got %v %v", mean.Shape(), mean.Float64Data())
+	}
+	if !reflect.DeepEqual(variance.Float64Data(), []float64{1.25, 0}) {
+		t.Errorf("LayerNormStats: expected variance [1.25 0], got %v", variance.Float64Data())
+	}
+	scale := 1 / math.Sqrt(1.25+1e-5)
+	for j, want := range []float64{-1.5 * scale, -0.5 * scale, 0.5 * scale, 1.5 * scale, 0, 0, 0, 0} {
+		if got := out.Float64Data()[j]; math.Abs(got-want) > 1e-12 {
+			t.Errorf("LayerNorm: element %d expected %v, got %v", j, want, got)
+		}
+	}
+
+	b, _ := NewNdArray([]int{2, 2}, []float32{1, 5, 3, 7})
+	cols, err := b.LayerNorm(0, 1e-5)
+	if err != nil {
+		t.Fatalf("LayerNorm: unexpected error: %v", err)
+	}
+	if cols.DType() != Float32 || cols.Float32Data()[0] >= 0 || cols.Float32Data()[2] <= 0 {
+		t.Errorf("LayerNorm: expected Float32 [-1 -1 1 1] approximately, got %v %v", cols.DType(), cols.data)
+	}
+
+	if _, err := a.LayerNorm(1, 0); err == nil {
+		t.Error("LayerNorm: expected error for non-positive eps")
+	}
+	if _, err := a.LayerNorm(2, 1e-5); err == nil {
+		t.Error("LayerNorm: expected error for out-of-range axis")
+	}
+}
+
+func TestMaskedSoftmax(t *testing.T) {
+	logits, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 3, 1000, 1000, 5})
+	mask, _ := NewNdArray([]int{2, 3}, []bool{true, false, true, false, false, false})
+	out, err := MaskedSoftmax(logits, mask, -1)
+	if err != nil {
+		t.Fatalf("MaskedSoftmax: unexpected error: %v", err)
+	}
+	e := math.Exp(-2)
+	expected := []float64{e / (1 + e), 0, 1 / (1 + e), 0, 0, 0}
+	for j, want := range expected {
+		if got := out.Float64Data()[j]; math.Abs(got-want) > 1e-12 {
+			t.Errorf("MaskedSoftmax: element %d expected %v, got %v", j, want, got)
+		}
+	}
+
+	// A [3] mask broadcasts across rows; large logits must not overflow.
+	row, _ := NewNdArray([]int{3}, []bool{true, true, false})
+	out, err = MaskedSoftmax(logits, row, 1)
+	if err != nil {
+		t.Fatalf("MaskedSoftmax broadcast: unexpected error: %v", err)
+	}
+	expected = []float64{1 / (1 + math.E), math.E / (1 + math.E), 0, 0.5, 0.5, 0}
+	for j, want := range expected {
+		if got := out.Float64Data()[j]; math.Abs(got-want) > 1e-12 {
+			t.Errorf("MaskedSoftmax broadcast: element %d expected %v, got %v", j, want, got)
+		}
+	}
+
+	l32, _ := NewNdArray([]int{2, 2}, []float32{0, 0, 1, 1})
+	m32, _ := NewNdArray([]int{2, 1}, []bool{true, false})
+	out, err = MaskedSoftmax(l32, m32, 0)
+	if err != nil {
+		t.Fatalf("MaskedSoftmax Float32: unexpected error: %v", err)
+	}
+	if out.DType() != Float32 || !reflect.DeepEqual(out.Float32Data(), []float32{1, 1, 0, 0}) {
+		t.Errorf("MaskedSoftmax Float32: expected [1 1 0 0], got %v %v", out.DType(), out.data)
+	}
+
+	if _, err := MaskedSoftmax(logits, logits, 1); err == nil {
+		t.Error("MaskedSoftmax: expected error for non-Bool mask")
+	}
+	bad, _ := NewNdArray([]int{2}, []bool{true, false})
+	if _, err := MaskedSoftmax(logits, bad, 1); err == nil {
+		t.Error("MaskedSoftmax: expected error for incompatible mask shape")
+	}
+	if _, err := MaskedSoftmax(logits, mask, 2); err == nil {
+		t.Error("MaskedSoftmax: expected error for out-of-range axis")
+	}
+}
+
+func TestTile(t *testing.T) {
+	a, _ := NewNdArray([]int{2}, []float64{1, 2})
+
+	res, err := a.Tile([]int{3})
+	if err != nil {
+		t.Fatalf("Tile: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(res.Float64Data(), []float64{1, 2, 1, 2, 1, 2}) {
+		t.Errorf("Tile: expected [1 2 1 2 1 2], got %v", res.Float64Data())
+	}
+
+	grid, _ := a.Tile([]int{2, 2})
+	if !reflect.DeepEqual(grid.Shape(), []int{2, 4}) || !reflect.DeepEqual(grid.Float64Data(), []float64{1, 2, 1, 2, 1, 2, 1, 2}) {
+		t.Errorf("Tile: expected [2 4] pattern, got %v %v", grid.Shape(), grid.Float64Data())
+	}
+
+	m, _ := NewNdArray([]int{2, 2}, []bool{true, false, false, true})
+	rows, _ := m.Tile([]int{2})
+	expected := []bool{true, false, true, false, false, true, false, true}
+	if !reflect.DeepEqual(rows.Shape(), []int{2, 4}) || !reflect.DeepEqual(rows.BoolData(), expected) {
+		t.Errorf("Tile: expected short reps to tile the last axis, got %v %v", rows.Shape(), rows.BoolData())
+	}
+
+	if _, err := a.Tile([]int{-1}); err == nil {
+		t.Error("Tile: expected error for negative repetitions")
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	a, _ := NewNdArray([]int{2}, []float64{1, 2})
+	res, err := a.Repeat(2, 0)
+	if err != nil {
+		t.Fatalf("Repeat: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(res.Float64Data(), []float64{1, 1, 2, 2}) {
+		t.Errorf("Repeat: expected [1 1 2 2], got %v", res.Float64Data())
+	}
+
+	m, _ := NewNdArray([]int{2, 2}, []int64{1, 2, 3, 4})
+	cols, _ := m.Repeat(3, -1)
+	expected := []int64{1, 1, 1, 2, 2, 2, 3, 3, 3, 4, 4, 4}
+	if !reflect.DeepEqual(cols.Shape(), []int{2, 6}) || !reflect.DeepEqual(cols.Int64Data(), expected) {
+		t.Errorf("Repeat: expected [2 6] %v, got %v %v", expected, cols.Shape(), cols.Int64Data())
+	}
+
+	rows, err := m.RepeatEach([]int{0, 2}, 0)
+	if err != nil {
+		t.Fatalf("RepeatEach: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(rows.Shape(), []int{2, 2}) || !reflect.DeepEqual(rows.Int64Data(), []int64{3, 4, 3, 4}) {
+		t.Errorf("RepeatEach: expected [2 2] [3 4 3 4], got %v %v", rows.Shape(), rows.Int64Data())
+	}
+
+	if _, err := m.RepeatEach([]int{1}, 0); err == nil {
+		t.Error("RepeatEach: expected error for count length mismatch")
+	}
+	if _, err := a.Repeat(-1, 0); err == nil {
+		t.Error("Repeat: expected error for negative repeats")
+	}
+	if _, err := a.Repeat(2, 1); err == nil {
+		t.Error("Repeat: expected error for out-of-range axis")
+	}
+}
+
+func TestSort(t *testing.T) {
+	nan := math.NaN()
+	a, _ := NewNdArray([]int{2, 3}, []float32{3, float32(nan), 1, 2, 5, 4})
+
+	rows, err := a.Sort(-1, false)
+	if err != nil {
+		t.Fatalf("Sort: unexpected error: %v", err)
+	}
+	got := rows.Float32Data()
+	if rows.DType() != Float32 || got[0] != 1 || got[1] != 3 || !math.IsNaN(float64(got[2])) ||
+		!reflect.DeepEqual(got[3:], []float32{2, 4, 5}) {
+		t.Errorf("Sort(-1): expected Float32 [1 3 NaN 2 4 5], got %v %v", rows.DType(), got)
+	}
+
+	cols, _ := a.Sort(0, true)
+	got = cols.Float32Data()
+	if !reflect.DeepEqual([]float32{got[0], got[2], got[3], got[5]}, []float32{3, 4, 2, 1}) || got[1] != 5 || !math.IsNaN(float64(got[4])) {
+		t.Errorf("Sort(0, desc): expected [3 5 4 2 NaN 1], got %v", got)
+	}
+	if !math.IsNaN(float64(a.Float32Data()[1])) || a.Float32Data()[0] != 3 {
+		t.Error("Sort: input should be unchanged")
+	}
+
+	ints, _ := NewNdArray([]int{4}, []int64{3, -1, 2, 0})
+	sorted, _ := ints.Sort(0, true)
+	if !reflect.DeepEqual(sorted.Int64Data(), []int64{3, 2, 0, -1}) {
+		t.Errorf("Sort Int64 desc: expected [3 2 0 -1], got %v", sorted.Int64Data())
+	}
+
+	if _, err := a.Sort(2, false); err == nil {
+		t.Error("Sort: expected error for out-of-range axis")
+	}
+}
@@ -2,6 +2,8 @@ package ndvek
 
 import (
 	"errors"
+	"fmt"
+	"math"
 
 	"github.com/viterin/vek"
 	"github.com/viterin/vek/vek32"
@@ -9,70 +11,173 @@ import (
 
 // AddInPlace performs element-wise addition: a += b.
 func (a *NdArray) AddInPlace(b *NdArray) error {
-	if !shapesEqual(a.shape, b.shape) {
-		return errors.New("shapes must be equal for in-place operation")
+	if err := a.checkInPlace(b); err != nil {
+		return err
 	}
-	if a.dtype == Float32 {
-		if b.dtype == Float64 {
-			return errors.New("cannot operate on Float32 with Float64 in-place")
-		}
+	switch a.dtype {
+	case Float32:
 		vek32.Add_Inplace(a.data.([]float32), b.data.([]float32))
-		return nil
+	case Int64:
+		d, n := a.data.([]int64), b.data.([]int64)
+		for i := range d {
+			d[i] += n[i]
+		}
+	default:
+		vek.Add_Inplace(a.data.([]float64), b.mustFloat64())
 	}
-	vek.Add_Inplace(a.data.([]float64), b.mustFloat64())
 	return nil
 }
 
 // SubtractInPlace performs element-wise subtraction: a -= b.
 func (a *NdArray) SubtractInPlace(b *NdArray) error {
-	if !shapesEqual(a.shape, b.shape) {
-		return errors.New("shapes must be equal for in-place operation")
+	if err := a.checkInPlace(b); err != nil {
+		return err
 	}
-	if a.dtype == Float32 {
-		if b.dtype == Float64 {
-			return errors.New("cannot operate on Float32 with Float64 in-place")
-		}
+	switch a.dtype {
+	case Float32:
 		vek32.Sub_Inplace(a.data.([]float32), b.data.([]float32))
-		return nil
+	case Int64:
+		d, n := a.data.([]int64), b.data.([]int64)
+		for i := range d {
+			d[i] -= n[i]
+		}
+	default:
+		vek.Sub_Inplace(a.data.([]float64), b.mustFloat64())
 	}
-	vek.Sub_Inplace(a.data.([]float64), b.mustFloat64())
 	return nil
 }
 
 // MultiplyInPlace performs element-wise multiplication: a *= b.
 func (a *NdArray) MultiplyInPlace(b *NdArray) error {
-	if !shapesEqual(a.shape, b.shape) {
-		return errors.New("shapes must be equal for in-place operation")
+	if err := a.checkInPlace(b); err != nil {
+		return err
 	}
-	if a.dtype == Float32 {
-		if b.dtype == Float64 {
-			return errors.New("cannot operate on Float32 with Float64 in-place")
-		}
+	switch a.dtype {
+	case Float32:
 		vek32.Mul_Inplace(a.data.([]float32), b.data.([]float32))
-		return nil
+	case Int64:
+		d, n := a.data.([]int64), b.data.([]int64)
+		for i := range d {
+			d[i] *= n[i]
+		}
+	default:
+		vek.Mul_Inplace(a.data.([]float64), b.mustFloat64())
 	}
-	vek.Mul_Inplace(a.data.([]float64), b.mustFloat64())
 	return nil
 }
 
 // DivideInPlace performs element-wise division: a /= b.
+// Int64 receivers are rejected, since true division cannot stay in Int64;
+// use Divide instead.
 func (a *NdArray) DivideInPlace(b *NdArray) error {
+	if err := a.checkInPlace(b); err != nil {
+		return err
+	}
+	switch a.dtype {
+	case Float32:
+		vek32.Div_Inplace(a.data.([]float32), b.data.([]float32))
+	case Int64:
+		return errors.New("DivideInPlace not supported for Int64 arrays; use Divide")
+	default:
+		vek.Div_Inplace(a.data.([]float64), b.mustFloat64())
+	}
+	return nil
+}
+
+// checkInPlace validates b as the operand of an element-wise in-place op on a:
+// the shapes must match, Bool is rejected, and Float32 and Int64 receivers
+// require an operand of the same dtype since the result keeps a's dtype.
+func (a *NdArray) checkInPlace(b *NdArray) error {
 	if !shapesEqual(a.shape, b.shape) {
 		return errors.New("shapes must be equal for in-place operation")
 	}
+	switch {
+	case a.dtype == Bool || b.dtype == Bool:
+		return errors.New("in-place arithmetic not supported for Bool arrays")
+	case a.dtype == Float32 && b.dtype != Float32:
+		return fmt.Errorf("cannot operate on Float32 with %s in-place", dtypeName(b.dtype))
+	case a.dtype == Int64 && b.dtype != Int64:
+		return fmt.Errorf("cannot operate on Int64 with %s in-place", dtypeName(b.dtype))
+	}
+	return nil
+}
+
+// mustBeFloat panics with a clear message unless a is a Float64 or Float32
+// array, for in-place ops that have no error return and cannot change dtype.
+func (a *NdArray) mustBeFloat(op string) {
+	if a.dtype != Float64 && a.dtype != Float32 {
+		panic(fmt.Sprintf("%s requires a floating-point array, got %s", op, dtypeName(a.dtype)))
+	}
+}
+
+// ApplyOpInPlace computes a = op(a, b) element-wise, broadcasting b into a's
+// shape and writing into a's buffer. Returns an error if broadcasting would
+// require a to grow.
+func (a *NdArray) ApplyOpInPlace(b *NdArray, op func(float64, float64) float64) error {
+	bShape, err := broadcastShapes(a.shape, b.shape)
+	if err != nil {
+		return err
+	}
+	if !shapesEqual(bShape, a.shape) {
+		return fmt.Errorf("cannot broadcast shape %v into %v in-place", b.shape, a.shape)
+	}
+	if a.dtype != Float64 && a.dtype != Float32 {
+		return fmt.Errorf("ApplyOpInPlace requires a floating-point array, got %s", dtypeName(a.dtype))
+	}
+	if a.dtype == Float32 && b.dtype == Float64 {
+		return errors.New("cannot operate on Float32 with Float64 in-place")
+	}
+	bData, err := b.toFloat64()
+	if err != nil {
+		return err
+	}
+
+	bIdx, _, _, err := BroadcastIndices(b.shape, a.shape)
+	if err != nil {
+		return err
+	}
+	for i, j := range bIdx {
+		if a.dtype == Float32 {
+			d := a.data.([]float32)
+			d[i] = float32(op(float64(d[i]), bData[j]))
+		} else {
+			d := a.data.([]float64)
+			d[i] = op(d[i], bData[j])
+		}
+	}
+	return nil
+}
+
+// EMAInPlace updates a as an exponential moving average of b:
+// a = decay*a + (1-decay)*b, with decay in [0, 1].
+func (a *NdArray) EMAInPlace(b *NdArray, decay float64) error {
+	if !(decay >= 0 && decay <= 1) {
+		return fmt.Errorf("decay must be in [0, 1], got %g", decay)
+	}
+	if a.dtype != Float64 && a.dtype != Float32 {
+		return fmt.Errorf("EMAInPlace requires a floating-point array, got %s", dtypeName(a.dtype))
+	}
+	if err := a.checkInPlace(b); err != nil {
+		return err
+	}
 	if a.dtype == Float32 {
-		if b.dtype == Float64 {
-			return errors.New("cannot operate on Float32 with Float64 in-place")
+		d, n := a.data.([]float32), b.data.([]float32)
+		w := float32(decay)
+		for i := range d {
+			d[i] = w*d[i] + (1-w)*n[i]
 		}
-		vek32.Div_Inplace(a.data.([]float32), b.data.([]float32))
 		return nil
 	}
-	vek.Div_Inplace(a.data.([]float64), b.mustFloat64())
+	d, n := a.data.([]float64), b.mustFloat64()
+	for i := range d {
+		d[i] = decay*d[i] + (1-decay)*n[i]
+	}
 	return nil
 }
 
 // AddScalarInPlace adds a scalar to each element: a += b.
 func (a *NdArray) AddScalarInPlace(b float64) {
+	a.mustBeFloat("AddScalarInPlace")
 	if a.dtype == Float32 {
 		vek32.AddNumber_Inplace(a.data.([]float32), float32(b))
 	} else {
@@ -82,6 +187,7 @@ func (a *NdArray) AddScalarInPlace(b float64) {
 
 // SubScalarInPlace subtracts a scalar from each element: a -= b.
 func (a *NdArray) SubScalarInPlace(b float64) {
+	a.mustBeFloat("SubScalarInPlace")
 	if a.dtype == Float32 {
 		vek32.SubNumber_Inplace(a.data.([]float32), float32(b))
 	} else {
@@ -91,6 +197,7 @@ func (a *NdArray) SubScalarInPlace(b float64) {
 
 // MulScalarInPlace multiplies each element by a scalar: a *= b.
 func (a *NdArray) MulScalarInPlace(b float64) {
+	a.mustBeFloat("MulScalarInPlace")
 	if a.dtype == Float32 {
 		vek32.MulNumber_Inplace(a.data.([]float32), float32(b))
 	} else {
@@ -100,6 +207,7 @@ func (a *NdArray) MulScalarInPlace(b float64) {
 
 // DivScalarInPlace divides each element by a scalar: a /= b.
 func (a *NdArray) DivScalarInPlace(b float64) {
+	a.mustBeFloat("DivScalarInPlace")
 	if a.dtype == Float32 {
 		vek32.DivNumber_Inplace(a.data.([]float32), float32(b))
 	} else {
@@ -107,26 +215,65 @@ func (a *NdArray) DivScalarInPlace(b float64) {
 	}
 }
 
+// Fill overwrites every element with value, reusing the existing buffer. The
+// value is narrowed to float32 or truncated to int64 to match the dtype; Bool
+// arrays are filled with value != 0.
+func (a *NdArray) Fill(value float64) {
+	switch a.dtype {
+	case Float32:
+		d := a.data.([]float32)
+		vek32.Repeat_Into(d, float32(value), len(d))
+	case Int64:
+		d := a.data.([]int64)
+		for i := range d {
+			d[i] = int64(value)
+		}
+	case Bool:
+		d := a.data.([]bool)
+		for i := range d {
+			d[i] = value != 0
+		}
+	default:
+		d := a.data.([]float64)
+		vek.Repeat_Into(d, value, len(d))
+	}
+}
+
 // AbsInPlace computes the absolute value in-place.
 func (a *NdArray) AbsInPlace() {
-	if a.dtype == Float32 {
+	switch a.dtype {
+	case Float32:
 		vek32.Abs_Inplace(a.data.([]float32))
-	} else {
+	case Int64:
+		d := a.data.([]int64)
+		for i, v := range d {
+			d[i] = max(v, -v)
+		}
+	default:
+		a.mustBeFloat("AbsInPlace")
 		vek.Abs_Inplace(a.data.([]float64))
 	}
 }
 
 // NegInPlace computes the negation in-place.
 func (a *NdArray) NegInPlace() {
-	if a.dtype == Float32 {
+	switch a.dtype {
+	case Float32:
 		vek32.Neg_Inplace(a.data.([]float32))
-	} else {
+	case Int64:
+		d := a.data.([]int64)
+		for i, v := range d {
+			d[i] = -v
+		}
+	default:
+		a.mustBeFloat("NegInPlace")
 		vek.Neg_Inplace(a.data.([]float64))
 	}
 }
 
 // SqrtInPlace computes the square root in-place.
 func (a *NdArray) SqrtInPlace() {
+	a.mustBeFloat("SqrtInPlace")
 	if a.dtype == Float32 {
 		vek32.Sqrt_Inplace(a.data.([]float32))
 	} else {
@@ -136,6 +283,7 @@ func (a *NdArray) SqrtInPlace() {
 
 // RoundInPlace rounds elements in-place.
 func (a *NdArray) RoundInPlace() {
+	a.mustBeFloat("RoundInPlace")
 	if a.dtype == Float32 {
 		vek32.Round_Inplace(a.data.([]float32))
 	} else {
@@ -145,6 +293,7 @@ func (a *NdArray) RoundInPlace() {
 
 // FloorInPlace floors elements in-place.
 func (a *NdArray) FloorInPlace() {
+	a.mustBeFloat("FloorInPlace")
 	if a.dtype == Float32 {
 		vek32.Floor_Inplace(a.data.([]float32))
 	} else {
@@ -154,6 +303,7 @@ func (a *NdArray) FloorInPlace() {
 
 // CeilInPlace ceils elements in-place.
 func (a *NdArray) CeilInPlace() {
+	a.mustBeFloat("CeilInPlace")
 	if a.dtype == Float32 {
 		vek32.Ceil_Inplace(a.data.([]float32))
 	} else {
@@ -163,6 +313,7 @@ func (a *NdArray) CeilInPlace() {
 
 // CumSumInPlace computes cumulative sum in-place.
 func (a *NdArray) CumSumInPlace() {
+	a.mustBeFloat("CumSumInPlace")
 	if a.dtype == Float32 {
 		vek32.CumSum_Inplace(a.data.([]float32))
 	} else {
@@ -172,9 +323,237 @@ func (a *NdArray) CumSumInPlace() {
 
 // CumProdInPlace computes cumulative product in-place.
 func (a *NdArray) CumProdInPlace() {
+	a.mustBeFloat("CumProdInPlace")
 	if a.dtype == Float32 {
 		vek32.CumProd_Inplace(a.data.([]float32))
 	} else {
 		vek.CumProd_Inplace(a.data.([]float64))
 	}
 }
+
+// ClipNormInPlace scales a in-place so that its L2 norm does not exceed
+// maxNorm, returning the norm before clipping. If the norm is not finite
+// (some element is ±Inf or NaN) a is left unchanged and the Inf or NaN norm
+// is returned for the caller to check, since scaling by maxNorm/Inf would
+// turn the whole array into zeros and NaN. It panics if maxNorm is negative.
+func (a *NdArray) ClipNormInPlace(maxNorm float64) float64 {
+	if !(maxNorm >= 0) {
+		panic(fmt.Sprintf("ClipNorm: maxNorm must be non-negative, got %g", maxNorm))
+	}
+	a.mustBeFloat("ClipNormInPlace")
+	norm := a.Norm()
+	if norm > maxNorm && !math.IsInf(norm, 1) {
+		a.MulScalarInPlace(maxNorm / norm)
+	}
+	return norm
+}
+
+// ClipInPlace clamps each element into [lo, hi] in-place. Infinite bounds
+// leave that side unbounded and NaN elements stay NaN. It panics if lo > hi.
+func (a *NdArray) ClipInPlace(lo, hi float64) {
+	if !(lo <= hi) {
+		panic(fmt.Sprintf("Clip: lower bound %g exceeds upper bound %g", lo, hi))
+	}
+	a.mustBeFloat("ClipInPlace")
+	if a.dtype == Float32 {
+		d := a.data.([]float32)
+		l, h := float32(lo), float32(hi)
+		for i, v := range d {
+			d[i] = min(max(v, l), h)
+		}
+		return
+	}
+	d := a.data.([]float64)
+	for i, v := range d {
+		d[i] = min(max(v, lo), hi)
+	}
+}
+
+// NormalizeSumInPlace divides each slice of a along axis by its sum so that
+// every slice totals one, e.g. turning counts into a row-stochastic matrix.
+// Slices summing to zero are left untouched; use NormalizeSumStrictInPlace to
+// reject them instead. Negative axes count from the end.
+func (a *NdArray) NormalizeSumInPlace(axis int) error {
+	return a.normalizeSumInPlace(axis, false)
+}
+
+// NormalizeSumStrictInPlace is NormalizeSumInPlace but returns an error,
+// leaving a unmodified, if any slice sums to zero.
+func (a *NdArray) NormalizeSumStrictInPlace(axis int) error {
+	return a.normalizeSumInPlace(axis, true)
+}
+
+func (a *NdArray) normalizeSumInPlace(axis int, strict bool) error {
+	if a.dtype != Float64 && a.dtype != Float32 {
+		return fmt.Errorf("NormalizeSumInPlace requires a floating-point array, got %s", dtypeName(a.dtype))
+	}
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return err
+	}
+	data := a.mustFloat64()
+	n := a.shape[ax]
+	inner := ProdInt(a.shape[ax+1:])
+	outer := ProdInt(a.shape[:ax])
+
+	sums := make([]float64, outer*inner)
+	for o := range outer {
+		for i := range inner {
+			base := o*n*inner + i
+			for j := range n {
+				sums[o*inner+i] += data[base+j*inner]
+			}
+			if strict && sums[o*inner+i] == 0 {
+				return fmt.Errorf("slice %d along axis %d sums to zero", o*inner+i, ax)
+			}
+		}
+	}
+
+	for o := range outer {
+		for i := range inner {
+			sum := sums[o*inner+i]
+			if sum == 0 {
+				continue
+			}
+			base := o*n*inner + i
+			for j := range n {
+				k := base + j*inner
+				if a.dtype == Float32 {
+					d := a.data.([]float32)
+					d[k] = float32(float64(d[k]) / sum)
+				} else {
+					data[k] /= sum
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// HardThresholdInPlace zeroes out elements with |x| < t in-place.
+func (a *NdArray) HardThresholdInPlace(t float64) {
+	a.mustBeFloat("HardThresholdInPlace")
+	if a.dtype == Float32 {
+		d := a.data.([]float32)
+		for i, v := range d {
+			if math.Abs(float64(v)) < t {
+				d[i] = 0
+			}
+		}
+		return
+	}
+	d := a.data.([]float64)
+	for i, v := range d {
+		if math.Abs(v) < t {
+			d[i] = 0
+		}
+	}
+}
+
+// SoftThresholdInPlace shrinks each element toward zero by t in-place. NaN
+// elements stay NaN. It panics if t is negative.
+func (a *NdArray) SoftThresholdInPlace(t float64) {
+	if !(t >= 0) {
+		panic(fmt.Sprintf("SoftThreshold: threshold must be non-negative, got %g", t))
+	}
+	a.mustBeFloat("SoftThresholdInPlace")
+	if a.dtype == Float32 {
+		d := a.data.([]float32)
+		for i, v := range d {
+			d[i] = float32(softThreshold(float64(v), t))
+		}
+		return
+	}
+	d := a.data.([]float64)
+	for i, v := range d {
+		d[i] = softThreshold(v, t)
+	}
+}
+
+// SnapToInPlace rounds each element to the nearest multiple of step in-place,
+// with halves rounded away from zero. It panics if step is not positive.
+func (a *NdArray) SnapToInPlace(step float64) {
+	if !(step > 0) {
+		panic(fmt.Sprintf("SnapTo: step must be positive, got %g", step))
+	}
+	a.mustBeFloat("SnapToInPlace")
+	if a.dtype == Float32 {
+		d := a.data.([]float32)
+		for i, v := range d {
+			d[i] = float32(math.Round(float64(v)/step) * step)
+		}
+		return
+	}
+	d := a.data.([]float64)
+	for i, v := range d {
+		d[i] = math.Round(v/step) * step
+	}
+}
+
+// SafeLogInPlace replaces each element x with log(max(x, eps)) in-place. NaN
+// elements stay NaN. It panics if eps is not positive or a is not a
+// floating-point array.
+func (a *NdArray) SafeLogInPlace(eps float64) {
+	if !(eps > 0) {
+		panic(fmt.Sprintf("SafeLog: eps must be positive, got %g", eps))
+	}
+	a.mustBeFloat("SafeLogInPlace")
+	if a.dtype == Float32 {
+		d := a.data.([]float32)
+		for i, v := range d {
+			d[i] = float32(math.Log(max(float64(v), eps)))
+		}
+		return
+	}
+	d := a.data.([]float64)
+	for i, v := range d {
+		d[i] = math.Log(max(v, eps))
+	}
+}
+
+func softThreshold(x, t float64) float64 {
+	switch {
+	case math.IsNaN(x):
+		return x
+	case x > t:
+		return x - t
+	case x < -t:
+		return x + t
+	default:
+		return 0
+	}
+}
+
+// ReplaceInPlace substitutes every element equal to from with to. A NaN from
+// matches NaN elements, which == comparison never would.
+func (a *NdArray) ReplaceInPlace(from, to float64) {
+	a.replaceClose(from, to, 0)
+}
+
+// replaceClose substitutes to for every element within tol of from, or for
+// NaN elements when from is NaN. For Float32 arrays from is compared at
+// float32 precision.
+func (a *NdArray) replaceClose(from, to, tol float64) {
+	a.mustBeFloat("ReplaceInPlace")
+	// The exact comparison catches infinities, where x-from is NaN.
+	match := func(x float64) bool { return x == from || math.Abs(x-from) <= tol }
+	if math.IsNaN(from) {
+		match = math.IsNaN
+	}
+	if a.dtype == Float32 {
+		from = float64(float32(from))
+		d := a.data.([]float32)
+		for i, v := range d {
+			if match(float64(v)) {
+				d[i] = float32(to)
+			}
+		}
+		return
+	}
+	d := a.data.([]float64)
+	for i, v := range d {
+		if match(v) {
+			d[i] = to
+		}
+	}
+}
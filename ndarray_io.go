@@ -0,0 +1,685 @@
+package ndvek
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/viterin/vek"
+)
+
+// Value implements driver.Valuer, encoding a 1-D numeric array in the
+// PostgreSQL text array format (e.g. "{1,2,3}") suitable for float8[] columns.
+func (a *NdArray) Value() (driver.Value, error) {
+	if len(a.shape) != 1 {
+		return nil, fmt.Errorf("Value requires a 1-D array, got shape %v", a.shape)
+	}
+	data, err := a.toFloat64()
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, v := range data {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		switch {
+		case math.IsInf(v, 1):
+			b.WriteString("Infinity")
+		case math.IsInf(v, -1):
+			b.WriteString("-Infinity")
+		default:
+			b.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+// Scan implements sql.Scanner, decoding a 1-D Float64 array from either the
+// PostgreSQL text array format ("{1,2,3}") or a JSON array ("[1,2,3]").
+func (a *NdArray) Scan(src any) error {
+	var text string
+	switch v := src.(type) {
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	case nil:
+		return errors.New("cannot scan NULL into NdArray")
+	default:
+		return fmt.Errorf("cannot scan %T into NdArray", src)
+	}
+
+	text = strings.TrimSpace(text)
+	var data []float64
+	var err error
+	switch {
+	case strings.HasPrefix(text, "{"):
+		data, err = parsePgArray(text)
+	case strings.HasPrefix(text, "["):
+		data, err = parseJSONList(text)
+	default:
+		err = fmt.Errorf("unrecognized array format %q", text)
+	}
+	if err != nil {
+		return err
+	}
+
+	*a = NdArray{shape: []int{len(data)}, data: data, dtype: Float64}
+	return nil
+}
+
+// parsePgArray parses a one-dimensional PostgreSQL text array of floats.
+func parsePgArray(text string) ([]float64, error) {
+	if !strings.HasSuffix(text, "}") {
+		return nil, fmt.Errorf("malformed array literal %q", text)
+	}
+	body := strings.TrimSpace(text[1 : len(text)-1])
+	if body == "" {
+		return []float64{}, nil
+	}
+	if strings.ContainsAny(body, "{}") {
+		return nil, errors.New("multi-dimensional array literals are not supported")
+	}
+
+	fields := strings.Split(body, ",")
+	data := make([]float64, len(fields))
+	for i, f := range fields {
+		f = strings.Trim(strings.TrimSpace(f), `"`)
+		if strings.EqualFold(f, "NULL") {
+			return nil, errors.New("NULL elements are not supported")
+		}
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid array element %q: %w", f, err)
+		}
+		data[i] = v
+	}
+	return data, nil
+}
+
+// parseJSONList parses a flat JSON array of numbers, rejecting null elements
+// rather than decoding them as zero.
+func parseJSONList(text string) ([]float64, error) {
+	var elems []*float64
+	if err := json.Unmarshal([]byte(text), &elems); err != nil {
+		return nil, err
+	}
+	data := make([]float64, len(elems))
+	for i, v := range elems {
+		if v == nil {
+			return nil, errors.New("NULL elements are not supported")
+		}
+		data[i] = *v
+	}
+	return data, nil
+}
+
+// jsonArray is the JSON wire form of an NdArray.
+type jsonArray struct {
+	Shape []int           `json:"shape"`
+	DType string          `json:"dtype"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// MarshalJSON implements json.Marshaler, encoding the array as
+// {"shape":[...],"dtype":"float64","data":[...]} with data flattened in
+// row-major order. Non-finite floats, which JSON numbers cannot represent,
+// are written as the strings "NaN", "Infinity" and "-Infinity".
+func (a *NdArray) MarshalJSON() ([]byte, error) {
+	var data []byte
+	var err error
+	switch a.dtype {
+	case Float64:
+		data = appendJSONFloats(nil, a.data.([]float64), 64)
+	case Float32:
+		data = appendJSONFloats(nil, vek.FromFloat32(a.data.([]float32)), 32)
+	case Bool:
+		data, err = json.Marshal(a.data.([]bool))
+	case Int64:
+		data, err = json.Marshal(a.data.([]int64))
+	default:
+		err = fmt.Errorf("unsupported dtype %d", a.dtype)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonArray{Shape: a.shape, DType: dtypeName(a.dtype), Data: data})
+}
+
+// appendJSONFloats appends data as a JSON array, formatting each value with
+// the shortest representation that round-trips at the given bit size.
+func appendJSONFloats(buf []byte, data []float64, bitSize int) []byte {
+	buf = append(buf, '[')
+	for i, v := range data {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		switch {
+		case math.IsNaN(v):
+			buf = append(buf, `"NaN"`...)
+		case math.IsInf(v, 1):
+			buf = append(buf, `"Infinity"`...)
+		case math.IsInf(v, -1):
+			buf = append(buf, `"-Infinity"`...)
+		default:
+			buf = strconv.AppendFloat(buf, v, 'g', -1, bitSize)
+		}
+	}
+	return append(buf, ']')
+}
+
+// parseJSONFloats decodes a JSON array of numbers and the non-finite strings
+// written by MarshalJSON, rounding each value to the given bit size.
+func parseJSONFloats(raw json.RawMessage, bitSize int) ([]float64, error) {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(raw, &elems); err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(elems))
+	for i, e := range elems {
+		switch string(e) {
+		case `"NaN"`:
+			out[i] = math.NaN()
+		case `"Infinity"`:
+			out[i] = math.Inf(1)
+		case `"-Infinity"`:
+			out[i] = math.Inf(-1)
+		default:
+			v, err := strconv.ParseFloat(string(e), bitSize)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array element %s", e)
+			}
+			out[i] = v
+		}
+	}
+	return out, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler for the format written by
+// MarshalJSON. The data length must match the product of the shape.
+func (a *NdArray) UnmarshalJSON(b []byte) error {
+	var raw jsonArray
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw.Shape == nil {
+		return errors.New("missing array shape")
+	}
+	if _, err := checkedSize(raw.Shape); err != nil {
+		return err
+	}
+
+	var data any
+	switch raw.DType {
+	case "float64":
+		vals, err := parseJSONFloats(raw.Data, 64)
+		if err != nil {
+			return err
+		}
+		data = vals
+	case "float32":
+		vals, err := parseJSONFloats(raw.Data, 32)
+		if err != nil {
+			return err
+		}
+		data = vek.ToFloat32(vals)
+	case "bool":
+		var vals []bool
+		if err := json.Unmarshal(raw.Data, &vals); err != nil {
+			return err
+		}
+		data = vals
+	case "int64":
+		var vals []int64
+		if err := json.Unmarshal(raw.Data, &vals); err != nil {
+			return err
+		}
+		data = vals
+	default:
+		return fmt.Errorf("unsupported dtype %q", raw.DType)
+	}
+
+	arr, err := NewNdArray(raw.Shape, data)
+	if err != nil {
+		return err
+	}
+	*a = *arr
+	return nil
+}
+
+// elemSize returns the encoded size in bytes of a single element of dtype.
+func elemSize(dtype DType) (int, error) {
+	switch dtype {
+	case Float64:
+		return 8, nil
+	case Float32:
+		return 4, nil
+	case Bool:
+		return 1, nil
+	case Int64:
+		return 8, nil
+	default:
+		return 0, fmt.Errorf("unsupported dtype %d", dtype)
+	}
+}
+
+// Bytes returns the little-endian encoding of the array data along with its
+// dtype and a copy of its shape. Bool elements are encoded as one byte each.
+func (a *NdArray) Bytes() ([]byte, DType, []int) {
+	shape := make([]int, len(a.shape))
+	copy(shape, a.shape)
+
+	var buf []byte
+	switch a.dtype {
+	case Float64:
+		d := a.data.([]float64)
+		buf = make([]byte, 8*len(d))
+		for i, v := range d {
+			binary.LittleEndian.PutUint64(buf[8*i:], math.Float64bits(v))
+		}
+	case Float32:
+		d := a.data.([]float32)
+		buf = make([]byte, 4*len(d))
+		for i, v := range d {
+			binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+		}
+	case Bool:
+		d := a.data.([]bool)
+		buf = make([]byte, len(d))
+		for i, v := range d {
+			if v {
+				buf[i] = 1
+			}
+		}
+	case Int64:
+		d := a.data.([]int64)
+		buf = make([]byte, 8*len(d))
+		for i, v := range d {
+			binary.LittleEndian.PutUint64(buf[8*i:], uint64(v))
+		}
+	}
+	return buf, a.dtype, shape
+}
+
+// checkedSize returns the number of elements in shape, rejecting negative
+// dimensions and shapes whose size (or any partial product of the nonzero
+// dimensions) overflows an int. Decoders run untrusted shapes through it
+// before allocating or calling NewNdArray, since ProdInt silently wraps.
+func checkedSize(shape []int) (int, error) {
+	size, nonzero := 1, 1
+	for _, dim := range shape {
+		if dim < 0 {
+			return 0, fmt.Errorf("invalid negative dimension in shape %v", shape)
+		}
+		if dim == 0 {
+			size = 0
+			continue
+		}
+		if nonzero > math.MaxInt/dim {
+			return 0, fmt.Errorf("shape %v is too large", shape)
+		}
+		nonzero *= dim
+	}
+	return size * nonzero, nil
+}
+
+// FromBytes decodes a little-endian byte buffer produced by Bytes into a new NdArray.
+func FromBytes(b []byte, dtype DType, shape []int) (*NdArray, error) {
+	size, err := elemSize(dtype)
+	if err != nil {
+		return nil, err
+	}
+	n, err := checkedSize(shape)
+	if err != nil {
+		return nil, err
+	}
+	if len(b)%size != 0 || len(b)/size != n {
+		return nil, fmt.Errorf("byte length %d does not match %d elements of size %d", len(b), n, size)
+	}
+
+	switch dtype {
+	case Float64:
+		d := make([]float64, n)
+		for i := range d {
+			d[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:]))
+		}
+		return NewNdArray(shape, d)
+	case Float32:
+		d := make([]float32, n)
+		for i := range d {
+			d[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+		}
+		return NewNdArray(shape, d)
+	case Int64:
+		d := make([]int64, n)
+		for i := range d {
+			d[i] = int64(binary.LittleEndian.Uint64(b[8*i:]))
+		}
+		return NewNdArray(shape, d)
+	default:
+		d := make([]bool, n)
+		for i := range d {
+			d[i] = b[i] != 0
+		}
+		return NewNdArray(shape, d)
+	}
+}
+
+// encodeArray serializes a single array as a dtype tag, rank, dimensions and
+// the raw little-endian element bytes.
+func encodeArray(a *NdArray) []byte {
+	raw, dtype, shape := a.Bytes()
+	buf := make([]byte, 0, 5+8*len(shape)+len(raw))
+	buf = append(buf, byte(dtype))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(shape)))
+	for _, dim := range shape {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(dim))
+	}
+	return append(buf, raw...)
+}
+
+// decodeArray is the inverse of encodeArray.
+func decodeArray(b []byte) (*NdArray, error) {
+	if len(b) < 5 {
+		return nil, errors.New("array payload too short")
+	}
+	dtype := DType(b[0])
+	rank := int(binary.LittleEndian.Uint32(b[1:]))
+	b = b[5:]
+	if len(b) < 8*rank {
+		return nil, errors.New("array payload too short for its rank")
+	}
+	shape := make([]int, rank)
+	for i := range shape {
+		dim := binary.LittleEndian.Uint64(b[8*i:])
+		if dim > math.MaxInt32 {
+			return nil, fmt.Errorf("invalid dimension %d", dim)
+		}
+		shape[i] = int(dim)
+	}
+	return FromBytes(b[8*rank:], dtype, shape)
+}
+
+var arrayMagic = []byte("NDVA\x01")
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is a magic
+// prefix, a dtype tag, the rank and dimensions, then the raw little-endian
+// element bytes, so float values including NaN and Inf round-trip exactly.
+func (a *NdArray) MarshalBinary() ([]byte, error) {
+	if _, err := elemSize(a.dtype); err != nil {
+		return nil, err
+	}
+	return append(slices.Clone(arrayMagic), encodeArray(a)...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler for the format
+// written by MarshalBinary.
+func (a *NdArray) UnmarshalBinary(b []byte) error {
+	if !bytes.HasPrefix(b, arrayMagic) {
+		return errors.New("not an ndvek binary array")
+	}
+	arr, err := decodeArray(b[len(arrayMagic):])
+	if err != nil {
+		return err
+	}
+	*a = *arr
+	return nil
+}
+
+var npyMagic = []byte("\x93NUMPY")
+
+// npyDescr maps dtypes to NumPy array-protocol type strings.
+var npyDescr = map[DType]string{
+	Float64: "<f8",
+	Float32: "<f4",
+	Bool:    "|b1",
+	Int64:   "<i8",
+}
+
+// npyMaxHeaderLen bounds the header length LoadNpy will read, matching the
+// default max_header_size limit of numpy.load.
+const npyMaxHeaderLen = 10000
+
+var (
+	npyDescrRe   = regexp.MustCompile(`'descr'\s*:\s*'([^']*)'`)
+	npyFortranRe = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	npyShapeRe   = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+// SaveNpy writes a in the NumPy .npy v1.0 format, readable by numpy.load.
+// Float64, Float32, Bool and Int64 map to <f8, <f4, |b1 and <i8.
+func SaveNpy(w io.Writer, a *NdArray) error {
+	descr, ok := npyDescr[a.dtype]
+	if !ok {
+		return fmt.Errorf("unsupported dtype %d", a.dtype)
+	}
+	dims := make([]string, len(a.shape))
+	for i, d := range a.shape {
+		dims[i] = strconv.Itoa(d)
+	}
+	shape := strings.Join(dims, ", ")
+	if len(dims) == 1 {
+		shape += ","
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", descr, shape)
+
+	// Pad with spaces so the data starts on a 64-byte boundary.
+	prefix := len(npyMagic) + 4
+	total := (prefix + len(header) + 1 + 63) / 64 * 64
+	header += strings.Repeat(" ", total-prefix-len(header)-1) + "\n"
+	if len(header) > math.MaxUint16 {
+		return errors.New("npy header too long")
+	}
+
+	buf := slices.Clone(npyMagic)
+	buf = append(buf, 1, 0)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(header)))
+	buf = append(buf, header...)
+	raw, _, _ := a.Bytes()
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+	_, err := w.Write(raw)
+	return err
+}
+
+// LoadNpy reads an array written by SaveNpy or numpy.save (format versions
+// 1.0 through 3.0). Arrays stored with fortran_order are converted to the
+// row-major layout used by NdArray.
+func LoadNpy(r io.Reader) (*NdArray, error) {
+	prefix := make([]byte, len(npyMagic)+2)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, fmt.Errorf("reading npy header: %w", err)
+	}
+	if !bytes.Equal(prefix[:len(npyMagic)], npyMagic) {
+		return nil, errors.New("not an npy file")
+	}
+	var headerLen int
+	switch major := prefix[len(npyMagic)]; major {
+	case 1:
+		var n [2]byte
+		if _, err := io.ReadFull(r, n[:]); err != nil {
+			return nil, fmt.Errorf("reading npy header: %w", err)
+		}
+		headerLen = int(binary.LittleEndian.Uint16(n[:]))
+	case 2, 3:
+		var n [4]byte
+		if _, err := io.ReadFull(r, n[:]); err != nil {
+			return nil, fmt.Errorf("reading npy header: %w", err)
+		}
+		headerLen = int(binary.LittleEndian.Uint32(n[:]))
+	default:
+		return nil, fmt.Errorf("unsupported npy format version %d", major)
+	}
+	if headerLen > npyMaxHeaderLen {
+		return nil, fmt.Errorf("npy header length %d exceeds %d", headerLen, npyMaxHeaderLen)
+	}
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("reading npy header: %w", err)
+	}
+
+	descr := npyDescrRe.FindSubmatch(header)
+	fortran := npyFortranRe.FindSubmatch(header)
+	shapeText := npyShapeRe.FindSubmatch(header)
+	if descr == nil || fortran == nil || shapeText == nil {
+		return nil, fmt.Errorf("malformed npy header %q", header)
+	}
+	var dtype DType
+	found := false
+	for dt, d := range npyDescr {
+		if d == string(descr[1]) {
+			dtype, found = dt, true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("unsupported npy dtype %q", descr[1])
+	}
+	var shape []int
+	for _, f := range strings.Split(string(shapeText[1]), ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		d, err := strconv.Atoi(f)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid npy shape (%s)", shapeText[1])
+		}
+		shape = append(shape, d)
+	}
+	if _, err := checkedSize(shape); err != nil {
+		return nil, err
+	}
+
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if string(fortran[1]) == "False" {
+		return FromBytes(raw, dtype, shape)
+	}
+	// Column-major data is the row-major layout of the reversed shape.
+	reversed := slices.Clone(shape)
+	slices.Reverse(reversed)
+	arr, err := FromBytes(raw, dtype, reversed)
+	if err != nil {
+		return nil, err
+	}
+	return arr.Transpose()
+}
+
+var bundleMagic = []byte("NDVB\x01")
+
+// SaveBundle writes several named arrays to w as a single container: a header
+// and index of names, payload sizes and CRC-32 checksums, followed by each
+// array's binary payload. Entries are written in name order.
+func SaveBundle(w io.Writer, arrays map[string]*NdArray) error {
+	names := make([]string, 0, len(arrays))
+	for name := range arrays {
+		if len(name) > math.MaxUint16 {
+			return fmt.Errorf("array name too long: %d bytes", len(name))
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	payloads := make([][]byte, len(names))
+	var index []byte
+	index = append(index, bundleMagic...)
+	index = binary.LittleEndian.AppendUint32(index, uint32(len(names)))
+	for i, name := range names {
+		payloads[i] = encodeArray(arrays[name])
+		index = binary.LittleEndian.AppendUint16(index, uint16(len(name)))
+		index = append(index, name...)
+		index = binary.LittleEndian.AppendUint64(index, uint64(len(payloads[i])))
+		index = binary.LittleEndian.AppendUint32(index, crc32.ChecksumIEEE(payloads[i]))
+	}
+
+	if _, err := w.Write(index); err != nil {
+		return err
+	}
+	for _, p := range payloads {
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadBundle reads a container written by SaveBundle, verifying each entry's checksum.
+func LoadBundle(r io.Reader) (map[string]*NdArray, error) {
+	header := make([]byte, len(bundleMagic)+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("reading bundle header: %w", err)
+	}
+	if !bytes.Equal(header[:len(bundleMagic)], bundleMagic) {
+		return nil, errors.New("not an ndvek bundle")
+	}
+	count := int(binary.LittleEndian.Uint32(header[len(bundleMagic):]))
+
+	type entry struct {
+		name string
+		size uint64
+		crc  uint32
+	}
+	// count is untrusted, so grow the index as entries are actually read
+	// rather than allocating it up front.
+	var entries []entry
+	var fixed [8]byte
+	for range count {
+		var e entry
+		if _, err := io.ReadFull(r, fixed[:2]); err != nil {
+			return nil, fmt.Errorf("reading bundle index: %w", err)
+		}
+		name := make([]byte, binary.LittleEndian.Uint16(fixed[:2]))
+		if _, err := io.ReadFull(r, name); err != nil {
+			return nil, fmt.Errorf("reading bundle index: %w", err)
+		}
+		e.name = string(name)
+		if _, err := io.ReadFull(r, fixed[:8]); err != nil {
+			return nil, fmt.Errorf("reading bundle index: %w", err)
+		}
+		e.size = binary.LittleEndian.Uint64(fixed[:8])
+		if _, err := io.ReadFull(r, fixed[:4]); err != nil {
+			return nil, fmt.Errorf("reading bundle index: %w", err)
+		}
+		e.crc = binary.LittleEndian.Uint32(fixed[:4])
+		entries = append(entries, e)
+	}
+
+	arrays := make(map[string]*NdArray, len(entries))
+	for _, e := range entries {
+		if _, dup := arrays[e.name]; dup {
+			return nil, fmt.Errorf("duplicate bundle entry %q", e.name)
+		}
+		payload, err := io.ReadAll(io.LimitReader(r, int64(e.size)))
+		if err != nil {
+			return nil, fmt.Errorf("reading entry %q: %w", e.name, err)
+		}
+		if uint64(len(payload)) != e.size {
+			return nil, fmt.Errorf("entry %q truncated", e.name)
+		}
+		if crc32.ChecksumIEEE(payload) != e.crc {
+			return nil, fmt.Errorf("checksum mismatch for entry %q", e.name)
+		}
+		a, err := decodeArray(payload)
+		if err != nil {
+			return nil, fmt.Errorf("decoding entry %q: %w", e.name, err)
+		}
+		arrays[e.name] = a
+	}
+	return arrays, nil
+}
@@ -0,0 +1,358 @@
+package ndvek
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSQLValueScan(t *testing.T) {
+	a, _ := NewNdArray([]int{3}, []float64{1, 2.5, math.Inf(-1)})
+	v, err := a.Value()
+	if err != nil {
+		t.Fatalf("Value: unexpected error: %v", err)
+	}
+	if v != "{1,2.5,-Infinity}" {
+		t.Errorf("Value: expected {1,2.5,-Infinity}, got %v", v)
+	}
+
+	var b NdArray
+	if err := b.Scan([]byte(v.(string))); err != nil {
+		t.Fatalf("Scan: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(b.Shape(), []int{3}) {
+		t.Errorf("Scan: expected shape [3], got %v", b.Shape())
+	}
+	if !reflect.DeepEqual(b.Float64Data(), a.Float64Data()) {
+		t.Errorf("Scan: expected %v, got %v", a.Float64Data(), b.Float64Data())
+	}
+
+	var c NdArray
+	if err := c.Scan("[4, 5, 6]"); err != nil {
+		t.Fatalf("Scan JSON: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(c.Float64Data(), []float64{4, 5, 6}) {
+		t.Errorf("Scan JSON: expected [4 5 6], got %v", c.Float64Data())
+	}
+
+	if err := c.Scan("{}"); err != nil || len(c.Float64Data()) != 0 {
+		t.Errorf("Scan: expected empty array for {}, got %v (err %v)", c.Float64Data(), err)
+	}
+	if err := c.Scan("{1,NULL}"); err == nil {
+		t.Error("Scan: expected error for NULL element")
+	}
+	if err := c.Scan("[1,null,3]"); err == nil {
+		t.Error("Scan JSON: expected error for null element")
+	}
+	if err := c.Scan("{{1,2},{3,4}}"); err == nil {
+		t.Error("Scan: expected error for multi-dimensional literal")
+	}
+	if err := c.Scan(nil); err == nil {
+		t.Error("Scan: expected error for NULL source")
+	}
+
+	m, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+	if _, err := m.Value(); err == nil {
+		t.Error("Value: expected error for 2-D array")
+	}
+}
+
+func TestBytesRoundTrip(t *testing.T) {
+	f64, _ := NewNdArray([]int{2, 2}, []float64{1, math.NaN(), math.Inf(1), -0.5})
+	f32, _ := NewNdArray([]int{3}, []float32{1.5, -2, 3})
+	b, _ := NewNdArray([]int{1, 2}, []bool{true, false})
+
+	for _, a := range []*NdArray{f64, f32, b} {
+		buf, dtype, shape := a.Bytes()
+		back, err := FromBytes(buf, dtype, shape)
+		if err != nil {
+			t.Fatalf("FromBytes: unexpected error: %v", err)
+		}
+		if back.DType() != a.DType() || !reflect.DeepEqual(back.Shape(), a.Shape()) {
+			t.Errorf("FromBytes: expected %v, got %v", a, back)
+		}
+		if back.String() != a.String() {
+			t.Errorf("FromBytes: expected %v, got %v", a, back)
+		}
+	}
+
+	buf, _, _ := f32.Bytes()
+	if len(buf) != 12 {
+		t.Errorf("Bytes: expected 12 bytes for 3 float32, got %d", len(buf))
+	}
+	if _, err := FromBytes(buf, Float64, []int{3}); err == nil {
+		t.Error("FromBytes: expected error for mismatched byte length")
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	f64, _ := NewNdArray([]int{2, 2}, []float64{1.5, math.NaN(), math.Inf(-1), 1e-300})
+	f32, _ := NewNdArray([]int{3}, []float32{0.1, 2, float32(math.Inf(1))})
+	b, _ := NewNdArray([]int{1, 2}, []bool{true, false})
+	i64, _ := NewNdArray([]int{2}, []int64{1<<62 + 1, -7})
+
+	for _, a := range []*NdArray{f64, f32, b, i64} {
+		enc, err := json.Marshal(a)
+		if err != nil {
+			t.Fatalf("MarshalJSON %v: unexpected error: %v", a.DType(), err)
+		}
+		var back NdArray
+		if err := json.Unmarshal(enc, &back); err != nil {
+			t.Fatalf("UnmarshalJSON %s: unexpected error: %v", enc, err)
+		}
+		if back.String() != a.String() || back.DType() != a.DType() {
+			t.Errorf("JSON round trip: expected %v, got %v", a, &back)
+		}
+	}
+
+	enc, _ := json.Marshal(f32)
+	if want := `{"shape":[3],"dtype":"float32","data":[0.1,2,"Infinity"]}`; string(enc) != want {
+		t.Errorf("MarshalJSON: expected %s, got %s", want, enc)
+	}
+
+	var bad NdArray
+	if err := json.Unmarshal([]byte(`{"shape":[2,2],"dtype":"float64","data":[1,2,3]}`), &bad); err == nil {
+		t.Error("UnmarshalJSON: expected error for data length mismatch")
+	}
+	if err := json.Unmarshal([]byte(`{"shape":[1],"dtype":"complex128","data":[1]}`), &bad); err == nil {
+		t.Error("UnmarshalJSON: expected error for unknown dtype")
+	}
+	if err := json.Unmarshal([]byte(`{"dtype":"float64","data":[1]}`), &bad); err == nil {
+		t.Error("UnmarshalJSON: expected error for missing shape")
+	}
+	if err := json.Unmarshal([]byte(`{"shape":[4294967296,4294967296],"dtype":"float64","data":[]}`), &bad); err == nil {
+		t.Error("UnmarshalJSON: expected error for shape whose size overflows")
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	f64, _ := NewNdArray([]int{2, 2}, []float64{1.5, math.NaN(), math.Inf(-1), -0.0})
+	f32, _ := NewNdArray([]int{3}, []float32{0.1, float32(math.NaN()), float32(math.Inf(1))})
+	b, _ := NewNdArray([]int{1, 2}, []bool{true, false})
+	i64, _ := NewNdArray([]int{2}, []int64{1<<62 + 1, -7})
+
+	for _, a := range []*NdArray{f64, f32, b, i64} {
+		enc, err := a.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary %v: unexpected error: %v", a.DType(), err)
+		}
+		var back NdArray
+		if err := back.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("UnmarshalBinary %v: unexpected error: %v", a.DType(), err)
+		}
+		raw, _, _ := a.Bytes()
+		backRaw, dtype, shape := back.Bytes()
+		if !bytes.Equal(raw, backRaw) || dtype != a.DType() || !reflect.DeepEqual(shape, a.Shape()) {
+			t.Errorf("binary round trip: expected %v, got %v", a, &back)
+		}
+	}
+
+	enc, _ := f64.MarshalBinary()
+	var bad NdArray
+	if err := bad.UnmarshalBinary(enc[:len(enc)-1]); err == nil {
+		t.Error("UnmarshalBinary: expected error for truncated data")
+	}
+	if err := bad.UnmarshalBinary(append([]byte("XXXX"), enc[4:]...)); err == nil {
+		t.Error("UnmarshalBinary: expected error for bad magic")
+	}
+
+	// Four dims of 2^16 wrap ProdInt to zero; with no data this must still fail.
+	hostile := append([]byte("NDVA\x01"), byte(Float64))
+	hostile = binary.LittleEndian.AppendUint32(hostile, 4)
+	for range 4 {
+		hostile = binary.LittleEndian.AppendUint64(hostile, 65536)
+	}
+	if err := bad.UnmarshalBinary(hostile); err == nil {
+		t.Errorf("UnmarshalBinary: expected error for overflowing shape, got %v", bad.Shape())
+	}
+
+	for _, shape := range [][]int{{65536, 65536, 65536, 65536}, {1 << 32, 1 << 32}, {2, -1}} {
+		if _, err := checkedSize(shape); err == nil {
+			t.Errorf("checkedSize(%v): expected error", shape)
+		}
+	}
+	if n, err := checkedSize([]int{0, 1 << 40, 3}); err != nil || n != 0 {
+		t.Errorf("checkedSize: expected 0 for a zero dim, got %d (err %v)", n, err)
+	}
+}
+
+func TestNpy(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []float64{0, 1, 2, 3, 4, 5})
+	var buf bytes.Buffer
+	if err := SaveNpy(&buf, a); err != nil {
+		t.Fatalf("SaveNpy: unexpected error: %v", err)
+	}
+
+	// Byte-for-byte what numpy.save writes for np.arange(6.0).reshape(2, 3).
+	dict := "{'descr': '<f8', 'fortran_order': False, 'shape': (2, 3), }"
+	header := dict + strings.Repeat(" ", 128-10-len(dict)-1) + "\n"
+	want := append([]byte("\x93NUMPY\x01\x00\x76\x00"), header...)
+	raw, _, _ := a.Bytes()
+	want = append(want, raw...)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("SaveNpy: expected %q, got %q", want, buf.Bytes())
+	}
+
+	for _, arr := range []*NdArray{
+		a,
+		NewScalar32(2.5),
+		FalseArray([]int{2, 1, 2}),
+		ZerosDType([]int{0}, Int64),
+	} {
+		buf.Reset()
+		if err := SaveNpy(&buf, arr); err != nil {
+			t.Fatalf("SaveNpy %v: unexpected error: %v", arr.DType(), err)
+		}
+		if raw, _, _ := arr.Bytes(); (buf.Len()-len(raw))%64 != 0 {
+			t.Errorf("SaveNpy: expected a 64-byte aligned header, got %d bytes", buf.Len()-len(raw))
+		}
+		back, err := LoadNpy(&buf)
+		if err != nil {
+			t.Fatalf("LoadNpy %v: unexpected error: %v", arr.DType(), err)
+		}
+		if back.String() != arr.String() {
+			t.Errorf("npy round trip: expected %v, got %v", arr, back)
+		}
+	}
+
+	fortran := "{'descr': '<f8', 'fortran_order': True, 'shape': (2, 3), }\n"
+	file := append([]byte("\x93NUMPY\x01\x00"), byte(len(fortran)), 0)
+	file = append(file, fortran...)
+	colMajor, _ := NewNdArray([]int{6}, []float64{0, 3, 1, 4, 2, 5})
+	colRaw, _, _ := colMajor.Bytes()
+	file = append(file, colRaw...)
+	got, err := LoadNpy(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("LoadNpy fortran_order: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got.Shape(), []int{2, 3}) || !reflect.DeepEqual(got.Float64Data(), []float64{0, 1, 2, 3, 4, 5}) {
+		t.Errorf("LoadNpy fortran_order: expected row-major 0..5, got %v", got)
+	}
+
+	bad := "{'descr': '<c16', 'fortran_order': False, 'shape': (1,), }\n"
+	file = append([]byte("\x93NUMPY\x01\x00"), byte(len(bad)), 0)
+	file = append(file, bad...)
+	if _, err := LoadNpy(bytes.NewReader(file)); err == nil {
+		t.Error("LoadNpy: expected error for unsupported dtype")
+	}
+	if _, err := LoadNpy(strings.NewReader("not numpy")); err == nil {
+		t.Error("LoadNpy: expected error for bad magic")
+	}
+
+	huge := "{'descr': '<f8', 'fortran_order': False, 'shape': (65536, 65536, 65536, 65536), }\n"
+	file = append([]byte("\x93NUMPY\x01\x00"), byte(len(huge)), 0)
+	file = append(file, huge...)
+	if _, err := LoadNpy(bytes.NewReader(file)); err == nil {
+		t.Error("LoadNpy: expected error for shape whose size overflows")
+	}
+	if _, err := LoadNpy(strings.NewReader("\x93NUMPY\x02\x00\xff\xff\xff\xff")); err == nil {
+		t.Error("LoadNpy: expected error for oversized v2 header length")
+	}
+}
+
+func TestNpyFixtures(t *testing.T) {
+	f8, _ := NewNdArray([]int{2, 3}, []float64{0, 1, 2, 3, 4, 5})
+	i8, _ := NewNdArray([]int{2, 3}, []int64{0, 1, 2, 3, 4, 5})
+	f4, _ := NewNdArray([]int{3}, []float32{0.5, -1, float32(math.Inf(1))})
+	b1, _ := NewNdArray([]int{2, 2}, []bool{true, false, false, true})
+
+	for file, want := range map[string]*NdArray{
+		"f8_2x3.npy":         f8,
+		"i8_2x3_fortran.npy": i8,
+		"f4_3.npy":           f4,
+		"b1_2x2.npy":         b1,
+	} {
+		content, err := os.ReadFile(filepath.Join("testdata", file))
+		if err != nil {
+			t.Fatalf("reading %s: %v", file, err)
+		}
+		got, err := LoadNpy(bytes.NewReader(content))
+		if err != nil {
+			t.Fatalf("LoadNpy %s: unexpected error: %v", file, err)
+		}
+		if got.DType() != want.DType() || got.String() != want.String() {
+			t.Errorf("LoadNpy %s: expected %v, got %v", file, want, got)
+		}
+		if file == "i8_2x3_fortran.npy" {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := SaveNpy(&buf, want); err != nil {
+			t.Fatalf("SaveNpy %s: unexpected error: %v", file, err)
+		}
+		if !bytes.Equal(buf.Bytes(), content) {
+			t.Errorf("SaveNpy %s: expected %q, got %q", file, content, buf.Bytes())
+		}
+	}
+}
+
+func TestBundle(t *testing.T) {
+	w, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 3, 4, 5, 6})
+	b, _ := NewNdArray([]int{3}, []float32{0.5, -1, 2})
+	m, _ := NewNdArray([]int{2}, []bool{true, false})
+	in := map[string]*NdArray{"weights": w, "bias": b, "mask": m}
+
+	var buf bytes.Buffer
+	if err := SaveBundle(&buf, in); err != nil {
+		t.Fatalf("SaveBundle: unexpected error: %v", err)
+	}
+	encoded := buf.Bytes()
+
+	out, err := LoadBundle(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("LoadBundle: unexpected error: %v", err)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("LoadBundle: expected %d arrays, got %d", len(in), len(out))
+	}
+	for name, a := range in {
+		got, ok := out[name]
+		if !ok {
+			t.Errorf("LoadBundle: missing entry %q", name)
+			continue
+		}
+		if got.String() != a.String() {
+			t.Errorf("LoadBundle %q: expected %v, got %v", name, a, got)
+		}
+	}
+
+	// Corrupt the last payload byte and expect a checksum failure
+	corrupt := bytes.Clone(encoded)
+	corrupt[len(corrupt)-1] ^= 0xff
+	if _, err := LoadBundle(bytes.NewReader(corrupt)); err == nil {
+		t.Error("LoadBundle: expected checksum error for corrupted payload")
+	}
+
+	if _, err := LoadBundle(bytes.NewReader([]byte("garbage data"))); err == nil {
+		t.Error("LoadBundle: expected error for bad magic")
+	}
+	if _, err := LoadBundle(bytes.NewReader(encoded[:len(encoded)-3])); err == nil {
+		t.Error("LoadBundle: expected error for truncated input")
+	}
+
+	// A huge entry count must fail on the missing index, not allocate it.
+	if _, err := LoadBundle(strings.NewReader("NDVB\x01\xff\xff\xff\xff")); err == nil {
+		t.Error("LoadBundle: expected error for entry count beyond the input")
+	}
+
+	// An entry whose dims multiply past MaxInt must not decode as empty.
+	payload := []byte{byte(Float64)}
+	payload = binary.LittleEndian.AppendUint32(payload, 4)
+	for range 4 {
+		payload = binary.LittleEndian.AppendUint64(payload, 65536)
+	}
+	hostile := append([]byte("NDVB\x01"), 1, 0, 0, 0, 1, 0, 'x')
+	hostile = binary.LittleEndian.AppendUint64(hostile, uint64(len(payload)))
+	hostile = binary.LittleEndian.AppendUint32(hostile, crc32.ChecksumIEEE(payload))
+	hostile = append(hostile, payload...)
+	if _, err := LoadBundle(bytes.NewReader(hostile)); err == nil {
+		t.Error("LoadBundle: expected error for overflowing shape")
+	}
+}
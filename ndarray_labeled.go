@@ -0,0 +1,66 @@
+package ndvek
+
+import (
+	"fmt"
+	"slices"
+)
+
+// LabeledArray pairs an NdArray with a name for each axis so that axes can be
+// addressed by name instead of position. The embedded NdArray keeps the full
+// integer-axis API available.
+type LabeledArray struct {
+	*NdArray
+	Labels []string
+}
+
+// NewLabeledArray attaches one unique, non-empty label per axis of a.
+func NewLabeledArray(a *NdArray, labels ...string) (*LabeledArray, error) {
+	if len(labels) != len(a.shape) {
+		return nil, fmt.Errorf("got %d labels for array of rank %d", len(labels), len(a.shape))
+	}
+	for i, name := range labels {
+		if name == "" {
+			return nil, fmt.Errorf("label for axis %d is empty", i)
+		}
+		if slices.Index(labels, name) != i {
+			return nil, fmt.Errorf("duplicate axis label %q", name)
+		}
+	}
+	return &LabeledArray{NdArray: a, Labels: slices.Clone(labels)}, nil
+}
+
+// AxisIndex returns the position of the axis with the given label.
+func (l *LabeledArray) AxisIndex(name string) (int, error) {
+	i := slices.Index(l.Labels, name)
+	if i < 0 {
+		return 0, fmt.Errorf("no axis labeled %q in %v", name, l.Labels)
+	}
+	return i, nil
+}
+
+// SumAxisNamed sums over the named axis, dropping it and its label.
+func (l *LabeledArray) SumAxisNamed(name string) (*LabeledArray, error) {
+	return l.reduceNamed(name, (*NdArray).SumAxes)
+}
+
+// MeanAxisNamed averages over the named axis, dropping it and its label.
+func (l *LabeledArray) MeanAxisNamed(name string) (*LabeledArray, error) {
+	return l.reduceNamed(name, (*NdArray).MeanAxes)
+}
+
+// MaxAxisNamed takes the maximum over the named axis, dropping it and its label.
+func (l *LabeledArray) MaxAxisNamed(name string) (*LabeledArray, error) {
+	return l.reduceNamed(name, (*NdArray).MaxAxes)
+}
+
+func (l *LabeledArray) reduceNamed(name string, reduce func(*NdArray, []int) (*NdArray, error)) (*LabeledArray, error) {
+	axis, err := l.AxisIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	out, err := reduce(l.NdArray, []int{axis})
+	if err != nil {
+		return nil, err
+	}
+	return &LabeledArray{NdArray: out, Labels: slices.Delete(slices.Clone(l.Labels), axis, axis+1)}, nil
+}
@@ -0,0 +1,54 @@
+package ndvek
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLabeledArray(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 3, 4, 5, 6})
+	l, err := NewLabeledArray(a, "time", "feature")
+	if err != nil {
+		t.Fatalf("NewLabeledArray: unexpected error: %v", err)
+	}
+
+	if i, _ := l.AxisIndex("feature"); i != 1 {
+		t.Errorf("AxisIndex: expected 1, got %d", i)
+	}
+	if _, err := l.AxisIndex("space"); err == nil {
+		t.Error("AxisIndex: expected error for unknown label")
+	}
+
+	sum, err := l.SumAxisNamed("time")
+	if err != nil {
+		t.Fatalf("SumAxisNamed: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(sum.Labels, []string{"feature"}) || !reflect.DeepEqual(sum.Float64Data(), []float64{5, 7, 9}) {
+		t.Errorf("SumAxisNamed: expected [feature] [5 7 9], got %v %v", sum.Labels, sum.Float64Data())
+	}
+
+	mean, _ := l.MeanAxisNamed("feature")
+	if !reflect.DeepEqual(mean.Labels, []string{"time"}) || !reflect.DeepEqual(mean.Float64Data(), []float64{2, 5}) {
+		t.Errorf("MeanAxisNamed: expected [time] [2 5], got %v %v", mean.Labels, mean.Float64Data())
+	}
+
+	maxRes, _ := l.MaxAxisNamed("feature")
+	if !reflect.DeepEqual(maxRes.Float64Data(), []float64{3, 6}) {
+		t.Errorf("MaxAxisNamed: expected [3 6], got %v", maxRes.Float64Data())
+	}
+	if !reflect.DeepEqual(l.Labels, []string{"time", "feature"}) {
+		t.Errorf("reductions should not modify the source labels, got %v", l.Labels)
+	}
+
+	// Integer-axis API stays available through embedding
+	if got, _ := l.SumAxes([]int{0}); !reflect.DeepEqual(got.Float64Data(), []float64{5, 7, 9}) {
+		t.Errorf("SumAxes via embedding: expected [5 7 9], got %v", got.Float64Data())
+	}
+
+	if _, err := NewLabeledArray(a, "time"); err == nil {
+		t.Error("NewLabeledArray: expected error for wrong label count")
+	}
+	if _, err := NewLabeledArray(a, "time", "time"); err == nil {
+		t.Error("NewLabeledArray: expected error for duplicate labels")
+	}
+}
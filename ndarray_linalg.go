@@ -0,0 +1,341 @@
+package ndvek
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/viterin/vek"
+	"github.com/viterin/vek/vek32"
+)
+
+const (
+	// transposeBlock is the tile edge used by the cache-blocked transpose.
+	transposeBlock = 32
+	// transposeBlockMin is the element count below which blocking isn't worth it.
+	transposeBlockMin = 64 * 64
+)
+
+// transpose2D writes the transpose of the row-major [rows, cols] matrix src
+// into dst as a row-major [cols, rows] matrix. Large matrices are copied in
+// square tiles so that both the reads and the writes stay cache-resident.
+func transpose2D[T any](dst, src []T, rows, cols int) {
+	if rows*cols < transposeBlockMin {
+		transposeNaive(dst, src, rows, cols)
+		return
+	}
+	for i0 := 0; i0 < rows; i0 += transposeBlock {
+		iEnd := min(i0+transposeBlock, rows)
+		for j0 := 0; j0 < cols; j0 += transposeBlock {
+			jEnd := min(j0+transposeBlock, cols)
+			for i := i0; i < iEnd; i++ {
+				row := src[i*cols : i*cols+cols]
+				for j := j0; j < jEnd; j++ {
+					dst[j*rows+i] = row[j]
+				}
+			}
+		}
+	}
+}
+
+// transposeNaive is the element-by-element transpose used for small matrices.
+func transposeNaive[T any](dst, src []T, rows, cols int) {
+	for i := range rows {
+		for j := range cols {
+			dst[j*rows+i] = src[i*cols+j]
+		}
+	}
+}
+
+// Transpose returns a new array with its axes permuted so that axis i of the
+// result is axis axes[i] of a, with the data physically reordered. With no
+// axes the order is reversed, which swaps rows and columns of a matrix.
+// axes must be a permutation of 0..rank-1; negative entries count from the end.
+func (a *NdArray) Transpose(axes ...int) (*NdArray, error) {
+	rank := len(a.shape)
+	if len(axes) == 0 {
+		axes = make([]int, rank)
+		for i := range axes {
+			axes[i] = rank - 1 - i
+		}
+	}
+	if len(axes) != rank {
+		return nil, fmt.Errorf("axes %v are not a permutation for array of rank %d", axes, rank)
+	}
+	perm, err := normalizeAxes(axes, rank)
+	if err != nil {
+		return nil, err
+	}
+
+	shape := make([]int, rank)
+	for i, ax := range perm {
+		shape[i] = a.shape[ax]
+	}
+	switch a.dtype {
+	case Float32:
+		return &NdArray{shape: shape, data: permute(a.data.([]float32), a.shape, perm), dtype: Float32}, nil
+	case Bool:
+		return &NdArray{shape: shape, data: permute(a.data.([]bool), a.shape, perm), dtype: Bool}, nil
+	case Int64:
+		return &NdArray{shape: shape, data: permute(a.data.([]int64), a.shape, perm), dtype: Int64}, nil
+	default:
+		return &NdArray{shape: shape, data: permute(a.data.([]float64), a.shape, perm), dtype: Float64}, nil
+	}
+}
+
+// MoveAxis returns a copy of a with axis src moved to position dst and the
+// other axes kept in order. Negative positions count from the end.
+func (a *NdArray) MoveAxis(src, dst int) (*NdArray, error) {
+	rank := len(a.shape)
+	from, err := normalizeAxis(src, rank)
+	if err != nil {
+		return nil, err
+	}
+	to, err := normalizeAxis(dst, rank)
+	if err != nil {
+		return nil, err
+	}
+	order := make([]int, 0, rank)
+	for ax := range rank {
+		if ax != from {
+			order = append(order, ax)
+		}
+	}
+	order = slices.Insert(order, to, from)
+	return a.Transpose(order...)
+}
+
+// AsChannelsLast converts a 4-D [B, C, H, W] array to [B, H, W, C].
+func (a *NdArray) AsChannelsLast() (*NdArray, error) {
+	if len(a.shape) != 4 {
+		return nil, fmt.Errorf("AsChannelsLast requires a 4-D array, got shape %v", a.shape)
+	}
+	return a.MoveAxis(1, 3)
+}
+
+// AsChannelsFirst converts a 4-D [B, H, W, C] array to [B, C, H, W].
+func (a *NdArray) AsChannelsFirst() (*NdArray, error) {
+	if len(a.shape) != 4 {
+		return nil, fmt.Errorf("AsChannelsFirst requires a 4-D array, got shape %v", a.shape)
+	}
+	return a.MoveAxis(3, 1)
+}
+
+// permute gathers the row-major src of the given shape into a new buffer with
+// its axes reordered by perm. Matrix transposes use the blocked kernel.
+func permute[T any](src []T, shape, perm []int) []T {
+	dst := make([]T, len(src))
+	if len(shape) == 2 && perm[0] == 1 {
+		transpose2D(dst, src, shape[0], shape[1])
+		return dst
+	}
+
+	rank := len(shape)
+	strides := make([]int, rank)
+	stride := 1
+	for i := rank - 1; i >= 0; i-- {
+		strides[i] = stride
+		stride *= shape[i]
+	}
+	// srcStrides[i] is the source step for a unit move along result axis i.
+	outShape := make([]int, rank)
+	srcStrides := make([]int, rank)
+	for i, ax := range perm {
+		outShape[i] = shape[ax]
+		srcStrides[i] = strides[ax]
+	}
+
+	coord := make([]int, rank)
+	offset := 0
+	for i := range dst {
+		dst[i] = src[offset]
+		for ax := rank - 1; ax >= 0; ax-- {
+			coord[ax]++
+			offset += srcStrides[ax]
+			if coord[ax] < outShape[ax] {
+				break
+			}
+			offset -= coord[ax] * srcStrides[ax]
+			coord[ax] = 0
+		}
+	}
+	return dst
+}
+
+// IsSymmetric reports whether the square 2-D array satisfies
+// |a[i,j] - a[j,i]| <= tol for every pair, stopping at the first violation.
+// NaN entries make the array asymmetric.
+func (a *NdArray) IsSymmetric(tol float64) (bool, error) {
+	if len(a.shape) != 2 || a.shape[0] != a.shape[1] {
+		return false, fmt.Errorf("IsSymmetric requires a square 2-D array, got shape %v", a.shape)
+	}
+	data, err := a.toFloat64()
+	if err != nil {
+		return false, err
+	}
+	n := a.shape[0]
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			if !(math.Abs(data[i*n+j]-data[j*n+i]) <= tol) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// MatMul returns the matrix product of a [m, k] and b [k, n] as a [m, n]
+// array. The result is Float32 when both inputs are Float32 and Float64
+// otherwise.
+func MatMul(a, b *NdArray) (*NdArray, error) {
+	if len(a.shape) != 2 || len(b.shape) != 2 {
+		return nil, fmt.Errorf("MatMul requires 2-D arrays, got shapes %v and %v", a.shape, b.shape)
+	}
+	m, k, n := a.shape[0], a.shape[1], b.shape[1]
+	if b.shape[0] != k {
+		return nil, fmt.Errorf("MatMul inner dimensions do not match: %v and %v", a.shape, b.shape)
+	}
+	if a.dtype == Bool || b.dtype == Bool {
+		return nil, errors.New("MatMul not supported for Bool arrays")
+	}
+	if m == 0 || k == 0 || n == 0 {
+		// vek rejects empty operands; an empty product is all zeros.
+		return fromFloat64([]int{m, n}, make([]float64, m*n), promoteDType(a, b)), nil
+	}
+
+	if a.dtype == Float32 && b.dtype == Float32 {
+		out := vek32.MatMul(a.data.([]float32), b.data.([]float32), k)
+		return &NdArray{shape: []int{m, n}, data: out, dtype: Float32}, nil
+	}
+	out := vek.MatMul(a.mustFloat64(), b.mustFloat64(), k)
+	return &NdArray{shape: []int{m, n}, data: out, dtype: Float64}, nil
+}
+
+// Outer returns the outer product of the 1-D arrays a [m] and b [n] as a
+// [m, n] array with result[i, j] = a[i]*b[j]. The result is Float32 when both
+// inputs are Float32 and Float64 otherwise.
+func Outer(a, b *NdArray) (*NdArray, error) {
+	if len(a.shape) != 1 || len(b.shape) != 1 {
+		return nil, fmt.Errorf("Outer requires 1-D arrays, got shapes %v and %v", a.shape, b.shape)
+	}
+	if a.dtype == Bool || b.dtype == Bool {
+		return nil, errors.New("Outer not supported for Bool arrays")
+	}
+	m, n := a.shape[0], b.shape[0]
+
+	if a.dtype == Float32 && b.dtype == Float32 {
+		x, y := a.data.([]float32), b.data.([]float32)
+		out := make([]float32, m*n)
+		for i, v := range x {
+			vek32.MulNumber_Into(out[i*n:(i+1)*n], y, v)
+		}
+		return &NdArray{shape: []int{m, n}, data: out, dtype: Float32}, nil
+	}
+	x, y := a.mustFloat64(), b.mustFloat64()
+	out := make([]float64, m*n)
+	for i, v := range x {
+		vek.MulNumber_Into(out[i*n:(i+1)*n], y, v)
+	}
+	return &NdArray{shape: []int{m, n}, data: out, dtype: Float64}, nil
+}
+
+// Diagonal returns the main diagonal of the 2-D array a as a 1-D array of
+// length min(rows, cols), preserving the dtype.
+func (a *NdArray) Diagonal() (*NdArray, error) {
+	if len(a.shape) != 2 {
+		return nil, fmt.Errorf("Diagonal requires a 2-D array, got shape %v", a.shape)
+	}
+	rows, cols := a.shape[0], a.shape[1]
+	n := min(rows, cols)
+	switch a.dtype {
+	case Float32:
+		return &NdArray{shape: []int{n}, data: diagonalOf(a.data.([]float32), n, cols), dtype: Float32}, nil
+	case Bool:
+		return &NdArray{shape: []int{n}, data: diagonalOf(a.data.([]bool), n, cols), dtype: Bool}, nil
+	case Int64:
+		return &NdArray{shape: []int{n}, data: diagonalOf(a.data.([]int64), n, cols), dtype: Int64}, nil
+	default:
+		return &NdArray{shape: []int{n}, data: diagonalOf(a.data.([]float64), n, cols), dtype: Float64}, nil
+	}
+}
+
+// Diag returns the square matrix with the 1-D array v on its main diagonal
+// and zeros (false for Bool) elsewhere, in v's dtype.
+func Diag(v *NdArray) (*NdArray, error) {
+	if len(v.shape) != 1 {
+		return nil, fmt.Errorf("Diag requires a 1-D array, got shape %v", v.shape)
+	}
+	n := v.shape[0]
+	out := ZerosDType([]int{n, n}, v.dtype)
+	switch v.dtype {
+	case Float32:
+		setDiagonal(out.data.([]float32), v.data.([]float32))
+	case Bool:
+		setDiagonal(out.data.([]bool), v.data.([]bool))
+	case Int64:
+		setDiagonal(out.data.([]int64), v.data.([]int64))
+	default:
+		setDiagonal(out.data.([]float64), v.data.([]float64))
+	}
+	return out, nil
+}
+
+// Trace returns the sum of the main diagonal of the square 2-D array a.
+func (a *NdArray) Trace() (float64, error) {
+	if len(a.shape) != 2 || a.shape[0] != a.shape[1] {
+		return 0, fmt.Errorf("Trace requires a square 2-D array, got shape %v", a.shape)
+	}
+	if a.dtype == Bool {
+		return 0, errors.New("Trace not supported for Bool arrays")
+	}
+	d, err := a.Diagonal()
+	if err != nil {
+		return 0, err
+	}
+	vals, err := d.toFloat64()
+	if err != nil {
+		return 0, err
+	}
+	return vek.Sum(vals), nil
+}
+
+// Eye returns the [n, n] Float64 identity matrix.
+func Eye(n int) *NdArray {
+	return EyeDType(n, Float64)
+}
+
+// EyeDType returns the [n, n] identity matrix in the given dtype (true on the
+// diagonal for Bool). It panics on an unknown dtype, like ZerosDType.
+func EyeDType(n int, dt DType) *NdArray {
+	out := ZerosDType([]int{n, n}, dt)
+	switch dt {
+	case Float32:
+		setDiagonal(out.data.([]float32), vek32.Ones(n))
+	case Bool:
+		setDiagonal(out.data.([]bool), slices.Repeat([]bool{true}, n))
+	case Int64:
+		setDiagonal(out.data.([]int64), slices.Repeat([]int64{1}, n))
+	default:
+		setDiagonal(out.data.([]float64), vek.Ones(n))
+	}
+	return out
+}
+
+// diagonalOf returns the first n diagonal entries of a row-major matrix with
+// the given number of columns.
+func diagonalOf[T any](src []T, n, cols int) []T {
+	out := make([]T, n)
+	for i := range out {
+		out[i] = src[i*cols+i]
+	}
+	return out
+}
+
+// setDiagonal writes v onto the diagonal of the row-major square matrix dst.
+func setDiagonal[T any](dst, v []T) {
+	n := len(v)
+	for i, x := range v {
+		dst[i*n+i] = x
+	}
+}
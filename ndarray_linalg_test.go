@@ -0,0 +1,315 @@
+package ndvek
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTranspose2DKernel(t *testing.T) {
+	// Sizes straddle the blocking threshold and use ragged tile edges
+	for _, dims := range [][2]int{{1, 1}, {3, 5}, {70, 65}, {129, 97}} {
+		rows, cols := dims[0], dims[1]
+		src := make([]float64, rows*cols)
+		for i := range src {
+			src[i] = float64(i)
+		}
+		got := make([]float64, len(src))
+		want := make([]float64, len(src))
+		transpose2D(got, src, rows, cols)
+		transposeNaive(want, src, rows, cols)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("transpose2D %dx%d: blocked result differs from naive", rows, cols)
+		}
+		if rows > 1 && got[1] != float64(cols) {
+			t.Errorf("transpose2D %dx%d: expected dst[1] = %d, got %v", rows, cols, cols, got[1])
+		}
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 3, 4, 5, 6})
+
+	res, err := a.Transpose()
+	if err != nil {
+		t.Fatalf("Transpose: unexpected error: %v", err)
+	}
+	expected := []float64{1, 4, 2, 5, 3, 6}
+	if !reflect.DeepEqual(res.Shape(), []int{3, 2}) || !reflect.DeepEqual(res.Float64Data(), expected) {
+		t.Errorf("Transpose: expected [3 2] %v, got %v %v", expected, res.Shape(), res.Float64Data())
+	}
+
+	b, _ := NewNdArray([]int{1, 2}, []bool{true, false})
+	res, _ = b.Transpose()
+	if res.DType() != Bool || !reflect.DeepEqual(res.Shape(), []int{2, 1}) {
+		t.Errorf("Transpose bool: expected Bool [2 1], got %v", res)
+	}
+
+	// Shape [2, 3, 4] holding 0..23; Transpose(2, 0, 1) gives [4, 2, 3]
+	data := make([]float64, 24)
+	for i := range data {
+		data[i] = float64(i)
+	}
+	c, _ := NewNdArray([]int{2, 3, 4}, data)
+	res, err = c.Transpose(2, 0, 1)
+	if err != nil {
+		t.Fatalf("Transpose(2, 0, 1): unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(res.Shape(), []int{4, 2, 3}) {
+		t.Errorf("Transpose(2, 0, 1): expected shape [4 2 3], got %v", res.Shape())
+	}
+	for k := range 4 {
+		for i := range 2 {
+			for j := range 3 {
+				got, _ := res.Get([]int{k, i, j})
+				want, _ := c.Get([]int{i, j, k})
+				if got != want {
+					t.Fatalf("Transpose(2, 0, 1): element [%d %d %d] expected %v, got %v", k, i, j, want, got)
+				}
+			}
+		}
+	}
+
+	rev, _ := c.Transpose()
+	if !reflect.DeepEqual(rev.Shape(), []int{4, 3, 2}) || rev.Float64Data()[1] != 12 {
+		t.Errorf("Transpose(): expected reversed axes [4 3 2], got %v", rev)
+	}
+	same, _ := c.Transpose(0, 1, 2)
+	if !reflect.DeepEqual(same.Float64Data(), data) {
+		t.Error("Transpose(0, 1, 2): expected an unchanged copy")
+	}
+
+	if _, err := c.Transpose(0, 1); err == nil {
+		t.Error("Transpose: expected error for too few axes")
+	}
+	if _, err := c.Transpose(0, 1, 1); err == nil {
+		t.Error("Transpose: expected error for repeated axes")
+	}
+	if _, err := c.Transpose(0, 1, 3); err == nil {
+		t.Error("Transpose: expected error for out-of-range axis")
+	}
+}
+
+func TestMoveAxis(t *testing.T) {
+	data := make([]float32, 2*3*4*5)
+	for i := range data {
+		data[i] = float32(i)
+	}
+	a, _ := NewNdArray([]int{2, 3, 4, 5}, data)
+
+	last, err := a.AsChannelsLast()
+	if err != nil {
+		t.Fatalf("AsChannelsLast: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(last.Shape(), []int{2, 4, 5, 3}) || last.DType() != Float32 {
+		t.Errorf("AsChannelsLast: expected Float32 [2 4 5 3], got %v %v", last.DType(), last.Shape())
+	}
+	got, _ := last.Get([]int{1, 2, 3, 1})
+	want, _ := a.Get([]int{1, 1, 2, 3})
+	if got != want {
+		t.Errorf("AsChannelsLast: expected %v at [1 2 3 1], got %v", want, got)
+	}
+
+	first, err := last.AsChannelsFirst()
+	if err != nil {
+		t.Fatalf("AsChannelsFirst: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(first.Float32Data(), data) {
+		t.Error("AsChannelsFirst: expected a round trip to restore the original layout")
+	}
+
+	moved, _ := a.MoveAxis(-1, 0)
+	if !reflect.DeepEqual(moved.Shape(), []int{5, 2, 3, 4}) {
+		t.Errorf("MoveAxis(-1, 0): expected shape [5 2 3 4], got %v", moved.Shape())
+	}
+	if _, err := a.MoveAxis(4, 0); err == nil {
+		t.Error("MoveAxis: expected error for out-of-range axis")
+	}
+	m, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+	if _, err := m.AsChannelsLast(); err == nil {
+		t.Error("AsChannelsLast: expected error for a 2-D array")
+	}
+}
+
+func TestIsSymmetric(t *testing.T) {
+	a, _ := NewNdArray([]int{3, 3}, []float64{2, 1, 0, 1, 3, 0.5, 0, 0.5, 4})
+	if ok, err := a.IsSymmetric(0); err != nil || !ok {
+		t.Errorf("IsSymmetric: expected true, got %v (err %v)", ok, err)
+	}
+
+	b, _ := NewNdArray([]int{2, 2}, []float32{1, 0.3, 0.30001, 1})
+	if ok, _ := b.IsSymmetric(0); ok {
+		t.Error("IsSymmetric: expected false with zero tolerance")
+	}
+	if ok, _ := b.IsSymmetric(1e-4); !ok {
+		t.Error("IsSymmetric: expected true within tolerance")
+	}
+
+	c, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 3, 4, 5, 6})
+	if _, err := c.IsSymmetric(0); err == nil {
+		t.Error("IsSymmetric: expected error for a non-square array")
+	}
+}
+
+func TestMatMul(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 3, 4, 5, 6})
+	b, _ := NewNdArray([]int{3, 2}, []float64{7, 8, 9, 10, 11, 12})
+
+	res, err := MatMul(a, b)
+	if err != nil {
+		t.Fatalf("MatMul: unexpected error: %v", err)
+	}
+	expected := []float64{58, 64, 139, 154}
+	if !reflect.DeepEqual(res.Shape(), []int{2, 2}) || !reflect.DeepEqual(res.Float64Data(), expected) {
+		t.Errorf("MatMul: expected [2 2] %v, got %v %v", expected, res.Shape(), res.Float64Data())
+	}
+
+	c, _ := NewNdArray([]int{2, 2}, []float32{1, 2, 3, 4})
+	d, _ := NewNdArray([]int{2, 1}, []float32{1, 1})
+	res, _ = MatMul(c, d)
+	if res.DType() != Float32 || !reflect.DeepEqual(res.Float32Data(), []float32{3, 7}) {
+		t.Errorf("MatMul float32: expected Float32 [3 7], got %v", res)
+	}
+
+	empty, _ := NewNdArray([]int{2, 0}, []float64{})
+	emptyT, _ := NewNdArray([]int{0, 3}, []float64{})
+	res, _ = MatMul(empty, emptyT)
+	if !reflect.DeepEqual(res.Shape(), []int{2, 3}) || res.Sum() != 0 {
+		t.Errorf("MatMul: expected [2 3] zeros for empty inner dimension, got %v", res)
+	}
+	for _, shapes := range [][2][]int{{{2, 3}, {3, 0}}, {{0, 3}, {3, 2}}} {
+		for _, dt := range []DType{Float64, Float32} {
+			x, y := ZerosDType(shapes[0], dt), ZerosDType(shapes[1], dt)
+			res, err := MatMul(x, y)
+			want := []int{shapes[0][0], shapes[1][1]}
+			if err != nil || res.DType() != dt || !reflect.DeepEqual(res.Shape(), want) {
+				t.Errorf("MatMul %v x %v %v: expected empty %v result, got %v (err %v)", shapes[0], shapes[1], dt, want, res, err)
+			}
+		}
+	}
+
+	if _, err := MatMul(a, a); err == nil {
+		t.Error("MatMul: expected error for mismatched inner dimensions")
+	}
+	v, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+	if _, err := MatMul(a, v); err == nil {
+		t.Error("MatMul: expected error for a 1-D operand")
+	}
+}
+
+func BenchmarkTranspose2D(b *testing.B) {
+	const n = 2048
+	src := make([]float64, n*n)
+	dst := make([]float64, n*n)
+	b.Run("Naive", func(b *testing.B) {
+		for b.Loop() {
+			transposeNaive(dst, src, n, n)
+		}
+	})
+	b.Run("Blocked", func(b *testing.B) {
+		for b.Loop() {
+			transpose2D(dst, src, n, n)
+		}
+	})
+}
+
+func TestOuter(t *testing.T) {
+	a, _ := NewNdArray([]int{2}, []float32{1, 2})
+	b, _ := NewNdArray([]int{3}, []float32{3, 4, 5})
+
+	res, err := Outer(a, b)
+	if err != nil {
+		t.Fatalf("Outer: unexpected error: %v", err)
+	}
+	if res.DType() != Float32 || !reflect.DeepEqual(res.Shape(), []int{2, 3}) ||
+		!reflect.DeepEqual(res.Float32Data(), []float32{3, 4, 5, 6, 8, 10}) {
+		t.Errorf("Outer: expected Float32 [2 3] [3 4 5 6 8 10], got %v %v %v", res.DType(), res.Shape(), res.data)
+	}
+
+	c, _ := NewNdArray([]int{2}, []float64{-1, 0.5})
+	mixed, _ := Outer(c, b)
+	if mixed.DType() != Float64 || !reflect.DeepEqual(mixed.Float64Data(), []float64{-3, -4, -5, 1.5, 2, 2.5}) {
+		t.Errorf("Outer: expected Float64 [-3 -4 -5 1.5 2 2.5], got %v %v", mixed.DType(), mixed.data)
+	}
+
+	m, _ := NewNdArray([]int{1, 2}, []float64{1, 2})
+	if _, err := Outer(m, c); err == nil {
+		t.Error("Outer: expected error for 2-D input")
+	}
+}
+
+func TestDiagonal(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []int64{1, 2, 3, 4, 5, 6})
+	d, err := a.Diagonal()
+	if err != nil {
+		t.Fatalf("Diagonal: unexpected error: %v", err)
+	}
+	if d.DType() != Int64 || !reflect.DeepEqual(d.Int64Data(), []int64{1, 5}) {
+		t.Errorf("Diagonal: expected Int64 [1 5], got %v %v", d.DType(), d.data)
+	}
+
+	v, _ := NewNdArray([]int{3}, []float32{1, 2, 3})
+	m, err := Diag(v)
+	if err != nil {
+		t.Fatalf("Diag: unexpected error: %v", err)
+	}
+	expected := []float32{1, 0, 0, 0, 2, 0, 0, 0, 3}
+	if m.DType() != Float32 || !reflect.DeepEqual(m.Shape(), []int{3, 3}) || !reflect.DeepEqual(m.Float32Data(), expected) {
+		t.Errorf("Diag: expected Float32 [3 3] %v, got %v %v %v", expected, m.DType(), m.Shape(), m.data)
+	}
+	if back, _ := m.Diagonal(); !reflect.DeepEqual(back.Float32Data(), v.Float32Data()) {
+		t.Errorf("Diagonal(Diag(v)): expected %v, got %v", v.Float32Data(), back.Float32Data())
+	}
+
+	eye := Eye(2)
+	if !reflect.DeepEqual(eye.Shape(), []int{2, 2}) || !reflect.DeepEqual(eye.Float64Data(), []float64{1, 0, 0, 1}) {
+		t.Errorf("Eye: expected [2 2] identity, got %v %v", eye.Shape(), eye.Float64Data())
+	}
+
+	if _, err := v.Diagonal(); err == nil {
+		t.Error("Diagonal: expected error for 1-D input")
+	}
+	if _, err := Diag(a); err == nil {
+		t.Error("Diag: expected error for 2-D input")
+	}
+}
+
+func TestEyeDType(t *testing.T) {
+	i32 := EyeDType(2, Float32)
+	if i32.DType() != Float32 || !reflect.DeepEqual(i32.Float32Data(), []float32{1, 0, 0, 1}) {
+		t.Errorf("EyeDType Float32: expected [1 0 0 1], got %v %v", i32.DType(), i32.data)
+	}
+	ib := EyeDType(2, Bool)
+	if ib.DType() != Bool || !reflect.DeepEqual(ib.data, []bool{true, false, false, true}) {
+		t.Errorf("EyeDType Bool: expected [true false false true], got %v %v", ib.DType(), ib.data)
+	}
+	ii := EyeDType(3, Int64)
+	if !reflect.DeepEqual(ii.Int64Data(), []int64{1, 0, 0, 0, 1, 0, 0, 0, 1}) {
+		t.Errorf("EyeDType Int64: expected 3x3 identity, got %v", ii.Int64Data())
+	}
+	if e := EyeDType(0, Float64); !reflect.DeepEqual(e.Shape(), []int{0, 0}) {
+		t.Errorf("EyeDType: expected shape [0 0], got %v", e.Shape())
+	}
+}
+
+func TestTrace(t *testing.T) {
+	a, _ := NewNdArray([]int{3, 3}, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	if tr, err := a.Trace(); err != nil || tr != 15 {
+		t.Errorf("Trace: expected 15, got %v (err %v)", tr, err)
+	}
+	i := EyeDType(4, Int64)
+	if tr, err := i.Trace(); err != nil || tr != 4 {
+		t.Errorf("Trace Int64: expected 4, got %v (err %v)", tr, err)
+	}
+
+	rect, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 3, 4, 5, 6})
+	if _, err := rect.Trace(); err == nil {
+		t.Error("Trace: expected error for non-square input")
+	}
+	vec, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+	if _, err := vec.Trace(); err == nil {
+		t.Error("Trace: expected error for 1-D input")
+	}
+	if _, err := EyeDType(2, Bool).Trace(); err == nil {
+		t.Error("Trace: expected error for Bool input")
+	}
+}
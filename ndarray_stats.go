@@ -0,0 +1,685 @@
+package ndvek
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/viterin/vek"
+)
+
+// uniqueCounts returns the distinct values of data in ascending order together
+// with the number of occurrences of each. NaN values are grouped together.
+func uniqueCounts(data []float64) ([]float64, []int) {
+	sorted := slices.Clone(data)
+	slices.SortFunc(sorted, cmp.Compare[float64])
+
+	var values []float64
+	var counts []int
+	for i, v := range sorted {
+		if i > 0 && cmp.Compare(v, sorted[i-1]) == 0 {
+			counts[len(counts)-1]++
+			continue
+		}
+		values = append(values, v)
+		counts = append(counts, 1)
+	}
+	return values, counts
+}
+
+// ValueCounts returns the distinct values of the flattened array and how often
+// each occurs, ordered by descending count (ties broken by ascending value).
+// values is Float32 for Float32 input and Float64 otherwise, so Int64 values
+// are widened; counts is Float64.
+func (a *NdArray) ValueCounts() (values *NdArray, counts *NdArray, err error) {
+	data, err := a.toFloat64()
+	if err != nil {
+		return nil, nil, err
+	}
+	uniq, cnt := uniqueCounts(data)
+
+	order := make([]int, len(uniq))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int { return cmp.Compare(cnt[j], cnt[i]) })
+
+	vals := make([]float64, len(uniq))
+	freqs := make([]float64, len(uniq))
+	for k, i := range order {
+		vals[k] = uniq[i]
+		freqs[k] = float64(cnt[i])
+	}
+	return fromFloat64([]int{len(uniq)}, vals, a.dtype), &NdArray{shape: []int{len(uniq)}, data: freqs, dtype: Float64}, nil
+}
+
+// WeightedQuantile returns the q-th quantile of the flattened array with each
+// element weighted by the corresponding entry of weights. Each element sits at
+// the midpoint of its weight in the cumulative distribution and the result is
+// linearly interpolated between neighbours, so equal weights reproduce the
+// (i+0.5)/n plotting positions. Weights must be non-negative with a positive sum.
+func (a *NdArray) WeightedQuantile(weights *NdArray, q float64) (float64, error) {
+	if !shapesEqual(a.shape, weights.shape) {
+		return 0, errors.New("weights must have the same shape as the array")
+	}
+	if !(q >= 0 && q <= 1) {
+		return 0, fmt.Errorf("quantile must be in [0, 1], got %g", q)
+	}
+	data, err := a.toFloat64()
+	if err != nil {
+		return 0, err
+	}
+	w, err := weights.toFloat64()
+	if err != nil {
+		return 0, err
+	}
+
+	type pair struct{ v, w float64 }
+	pairs := make([]pair, 0, len(data))
+	total := 0.0
+	for i, v := range data {
+		if !(w[i] >= 0) {
+			return 0, fmt.Errorf("weights must be non-negative, got %g", w[i])
+		}
+		if w[i] > 0 {
+			pairs = append(pairs, pair{v, w[i]})
+			total += w[i]
+		}
+	}
+	if total == 0 {
+		return 0, errors.New("weights must have a positive sum")
+	}
+	slices.SortFunc(pairs, func(x, y pair) int { return cmp.Compare(x.v, y.v) })
+
+	target := q * total
+	cum, prevPos := 0.0, 0.0
+	for i, p := range pairs {
+		pos := cum + p.w/2
+		if target <= pos {
+			if i == 0 {
+				return p.v, nil
+			}
+			prev := pairs[i-1].v
+			return prev + (p.v-prev)*(target-prevPos)/(pos-prevPos), nil
+		}
+		cum += p.w
+		prevPos = pos
+	}
+	return pairs[len(pairs)-1].v, nil
+}
+
+// CredibleInterval returns the equal-tailed interval containing prob of the
+// draws along axis: the (1-prob)/2 and (1+prob)/2 quantiles, linearly
+// interpolated as in Quantile. The axis is dropped from both results.
+func (a *NdArray) CredibleInterval(prob float64, axis int) (lower *NdArray, upper *NdArray, err error) {
+	tail := (1 - prob) / 2
+	return a.intervalAlongAxis(prob, axis, func(x []float64) (float64, float64) {
+		return vek.Quantile(x, tail), vek.Quantile(x, 1-tail)
+	})
+}
+
+// HPDInterval returns the highest posterior density interval along axis: the
+// narrowest range spanning ceil(prob*n) of the n draws. Unlike
+// CredibleInterval its bounds are always observed draws, and it is shorter
+// for skewed posteriors.
+func (a *NdArray) HPDInterval(prob float64, axis int) (lower *NdArray, upper *NdArray, err error) {
+	return a.intervalAlongAxis(prob, axis, func(x []float64) (float64, float64) {
+		slices.Sort(x)
+		k := max(int(math.Ceil(prob*float64(len(x)))), 1)
+		best := 0
+		for i := 1; i+k <= len(x); i++ {
+			if x[i+k-1]-x[i] < x[best+k-1]-x[best] {
+				best = i
+			}
+		}
+		return x[best], x[best+k-1]
+	})
+}
+
+// intervalAlongAxis reduces each slice along axis to a (lower, upper) pair.
+// bounds may reorder its argument.
+func (a *NdArray) intervalAlongAxis(prob float64, axis int, bounds func([]float64) (float64, float64)) (*NdArray, *NdArray, error) {
+	if !(prob > 0 && prob < 1) {
+		return nil, nil, fmt.Errorf("interval probability must be in (0, 1), got %g", prob)
+	}
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, nil, err
+	}
+	if a.shape[ax] == 0 {
+		return nil, nil, errors.New("cannot compute an interval over an empty axis")
+	}
+	var upper []float64
+	shape, lower, err := a.reduceAxesFloat64([]int{ax}, false, func(x []float64) float64 {
+		lo, hi := bounds(x)
+		upper = append(upper, hi)
+		return lo
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return fromFloat64(shape, lower, a.dtype), fromFloat64(slices.Clone(shape), upper, a.dtype), nil
+}
+
+// Autocorr returns the autocorrelation of the 1-D series a at lags
+// 0..maxLag as a Float64 array, using the standard biased estimator
+// r_k = sum_t (x_t - m)(x_{t+k} - m) / sum_t (x_t - m)^2.
+func (a *NdArray) Autocorr(maxLag int) (*NdArray, error) {
+	centered, err := a.centeredSeries()
+	if err != nil {
+		return nil, err
+	}
+	if maxLag < 0 || maxLag >= len(centered) {
+		return nil, fmt.Errorf("maxLag must be in [0, %d), got %d", len(centered), maxLag)
+	}
+	out := make([]float64, maxLag+1)
+	for k := range out {
+		out[k] = autocorrAt(centered, k)
+	}
+	return &NdArray{shape: []int{maxLag + 1}, data: out, dtype: Float64}, nil
+}
+
+// EffectiveSampleSize estimates the number of independent draws in the 1-D
+// MCMC chain a as n / tau, where the integrated autocorrelation time tau is
+// summed with Geyer's initial positive sequence rule: consecutive lag pairs
+// r_{2m} + r_{2m+1} are accumulated while they remain positive. As in Stan
+// and ArviZ, the estimate is capped at n*log10(n) so anti-correlated chains,
+// whose tau can approach zero, do not report an absurdly large ESS.
+func (a *NdArray) EffectiveSampleSize() (float64, error) {
+	centered, err := a.centeredSeries()
+	if err != nil {
+		return 0, err
+	}
+	n := len(centered)
+	tau := -1.0
+	for lag := 0; lag+1 < n; lag += 2 {
+		pair := autocorrAt(centered, lag) + autocorrAt(centered, lag+1)
+		if pair <= 0 {
+			break
+		}
+		tau += 2 * pair
+	}
+	nf := float64(n)
+	return nf / max(tau, 1/math.Log10(nf)), nil
+}
+
+// Thin keeps every step-th slice of a along axis, starting with the first.
+// Negative axes count from the end.
+func (a *NdArray) Thin(step int, axis int) (*NdArray, error) {
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, err
+	}
+	if step <= 0 {
+		return nil, fmt.Errorf("thinning step must be positive, got %d", step)
+	}
+	idx := make([]int, 0, (a.shape[ax]+step-1)/step)
+	for i := 0; i < a.shape[ax]; i += step {
+		idx = append(idx, i)
+	}
+	return a.takeAlongAxis(ax, idx), nil
+}
+
+// Burn drops the first n slices of a along axis, as when discarding MCMC
+// warm-up draws. At least one slice must remain.
+func (a *NdArray) Burn(n int, axis int) (*NdArray, error) {
+	ax, err := normalizeAxis(axis, len(a.shape))
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n >= a.shape[ax] {
+		return nil, fmt.Errorf("burn-in must be in [0, %d), got %d", a.shape[ax], n)
+	}
+	idx := make([]int, 0, a.shape[ax]-n)
+	for i := n; i < a.shape[ax]; i++ {
+		idx = append(idx, i)
+	}
+	return a.takeAlongAxis(ax, idx), nil
+}
+
+// RHat computes the Gelman-Rubin potential scale reduction factor for each
+// parameter across equally shaped [draws, params] chains, returning a
+// [params] Float64 array. Values near 1 indicate the chains have mixed.
+// A parameter whose chains are all constant gives 1 if they share the same
+// value and an error otherwise, since the ratio is then undefined.
+func RHat(chains []*NdArray) (*NdArray, error) {
+	if len(chains) < 2 {
+		return nil, fmt.Errorf("RHat requires at least 2 chains, got %d", len(chains))
+	}
+	shape := chains[0].shape
+	if len(shape) != 2 || shape[0] < 2 {
+		return nil, fmt.Errorf("RHat requires [draws, params] chains with at least 2 draws, got shape %v", shape)
+	}
+	n, p := shape[0], shape[1]
+	data := make([][]float64, len(chains))
+	for c, chain := range chains {
+		if !shapesEqual(chain.shape, shape) {
+			return nil, fmt.Errorf("chain %d has shape %v, expected %v", c, chain.shape, shape)
+		}
+		d, err := chain.toFloat64()
+		if err != nil {
+			return nil, err
+		}
+		data[c] = d
+	}
+
+	m := float64(len(chains))
+	out := make([]float64, p)
+	means := make([]float64, len(chains))
+	for j := range p {
+		var within float64
+		for c, d := range data {
+			var sum, sumSq float64
+			for i := range n {
+				sum += d[i*p+j]
+			}
+			means[c] = sum / float64(n)
+			for i := range n {
+				dev := d[i*p+j] - means[c]
+				sumSq += dev * dev
+			}
+			within += sumSq / float64(n-1)
+		}
+		within /= m
+
+		grand := vek.Mean(means)
+		var between float64 // B / n
+		for _, mu := range means {
+			between += (mu - grand) * (mu - grand)
+		}
+		between /= m - 1
+
+		switch {
+		case within == 0 && between == 0:
+			// Identical constant chains agree perfectly.
+			out[j] = 1
+		case within == 0:
+			return nil, fmt.Errorf("RHat is undefined for parameter %d: chains are constant at different values", j)
+		default:
+			pooled := float64(n-1)/float64(n)*within + between
+			out[j] = math.Sqrt(pooled / within)
+		}
+	}
+	return &NdArray{shape: []int{p}, data: out, dtype: Float64}, nil
+}
+
+// centeredSeries returns the mean-centered values of a 1-D array with
+// non-zero variance.
+func (a *NdArray) centeredSeries() ([]float64, error) {
+	if len(a.shape) != 1 || a.shape[0] < 2 {
+		return nil, fmt.Errorf("autocorrelation requires a 1-D array of at least 2 elements, got shape %v", a.shape)
+	}
+	data, err := a.toFloat64()
+	if err != nil {
+		return nil, err
+	}
+	centered := vek.SubNumber(data, vek.Mean(data))
+	if vek.Dot(centered, centered) == 0 {
+		return nil, errors.New("autocorrelation is undefined for a constant series")
+	}
+	return centered, nil
+}
+
+// autocorrAt returns the biased autocorrelation of centered data at lag k.
+func autocorrAt(centered []float64, k int) float64 {
+	n := len(centered)
+	return vek.Dot(centered[:n-k], centered[k:]) / vek.Dot(centered, centered)
+}
+
+// uniformBin returns the bin of v among bins equal-width bins spanning [lo, hi].
+// The upper edge is included in the last bin. ok is false for values outside
+// the range, including NaN.
+func uniformBin(v, lo, hi float64, bins int) (bin int, ok bool) {
+	if !(v >= lo && v <= hi) {
+		return 0, false
+	}
+	bin = int((v - lo) / (hi - lo) * float64(bins))
+	return min(bin, bins-1), true
+}
+
+// Histogram2d bins paired samples from the 1-D arrays x and y into a
+// [binsX, binsY] Float64 grid of counts over equal-width bins spanning rangeX
+// and rangeY. Points outside either range are dropped.
+func Histogram2d(x, y *NdArray, binsX, binsY int, rangeX, rangeY [2]float64) (counts *NdArray, err error) {
+	if len(x.shape) != 1 || len(y.shape) != 1 {
+		return nil, errors.New("Histogram2d requires 1-D arrays")
+	}
+	if x.shape[0] != y.shape[0] {
+		return nil, errors.New("Histogram2d requires arrays of equal length")
+	}
+	if binsX <= 0 || binsY <= 0 {
+		return nil, fmt.Errorf("bin counts must be positive, got %d and %d", binsX, binsY)
+	}
+	if !(rangeX[0] < rangeX[1]) || !(rangeY[0] < rangeY[1]) {
+		return nil, fmt.Errorf("invalid ranges %v and %v", rangeX, rangeY)
+	}
+	xData, err := x.toFloat64()
+	if err != nil {
+		return nil, err
+	}
+	yData, err := y.toFloat64()
+	if err != nil {
+		return nil, err
+	}
+
+	grid := make([]float64, binsX*binsY)
+	for i := range xData {
+		bx, okX := uniformBin(xData[i], rangeX[0], rangeX[1], binsX)
+		by, okY := uniformBin(yData[i], rangeY[0], rangeY[1], binsY)
+		if okX && okY {
+			grid[bx*binsY+by]++
+		}
+	}
+	return &NdArray{shape: []int{binsX, binsY}, data: grid, dtype: Float64}, nil
+}
+
+// ConstantColumns returns the indices of the columns of the 2-D array a whose
+// range (max - min) is at most tol, in ascending order. Columns containing NaN
+// are never reported as constant.
+func (a *NdArray) ConstantColumns(tol float64) ([]int, error) {
+	if len(a.shape) != 2 {
+		return nil, fmt.Errorf("ConstantColumns requires a 2-D array, got shape %v", a.shape)
+	}
+	data, err := a.toFloat64()
+	if err != nil {
+		return nil, err
+	}
+	n, p := a.shape[0], a.shape[1]
+	var cols []int
+	for j := range p {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for i := range n {
+			v := data[i*p+j]
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		if n == 0 || hi-lo <= tol {
+			cols = append(cols, j)
+		}
+	}
+	return cols, nil
+}
+
+// standardizeColumns returns the columns of the row-major [n, p] matrix data
+// centered and scaled to unit population variance, laid out as a row-major
+// [p, n] matrix. Zero-variance columns are filled with NaN.
+func standardizeColumns(data []float64, n, p int) []float64 {
+	out := make([]float64, p*n)
+	for j := range p {
+		col := out[j*n : (j+1)*n]
+		for i := range n {
+			col[i] = data[i*p+j]
+		}
+		vek.SubNumber_Inplace(col, vek.Mean(col))
+		std := vek.Norm(col) / math.Sqrt(float64(n))
+		if std == 0 {
+			vek.MulNumber_Inplace(col, math.NaN())
+			continue
+		}
+		vek.DivNumber_Inplace(col, std)
+	}
+	return out
+}
+
+// CrossCorr computes the Pearson correlation between every column of a [n, p]
+// and every column of b [n, q], returning a [p, q] matrix. Correlations with a
+// zero-variance column are NaN.
+func CrossCorr(a, b *NdArray) (*NdArray, error) {
+	if len(a.shape) != 2 || len(b.shape) != 2 {
+		return nil, errors.New("CrossCorr requires 2-D arrays")
+	}
+	n, p, q := a.shape[0], a.shape[1], b.shape[1]
+	if b.shape[0] != n {
+		return nil, fmt.Errorf("CrossCorr requires equal row counts, got %d and %d", n, b.shape[0])
+	}
+	if n == 0 {
+		return nil, errors.New("CrossCorr requires at least one row")
+	}
+	if p == 0 || q == 0 {
+		return fromFloat64([]int{p, q}, []float64{}, promoteDType(a, b)), nil
+	}
+	aData, err := a.toFloat64()
+	if err != nil {
+		return nil, err
+	}
+	bData, err := b.toFloat64()
+	if err != nil {
+		return nil, err
+	}
+
+	za := standardizeColumns(aData, n, p)
+	zb := standardizeColumns(bData, n, q)
+	// zb is [q, n]; transpose it back to [n, q] for the product za·zb.
+	zbT := make([]float64, n*q)
+	for j := range q {
+		for i := range n {
+			zbT[i*q+j] = zb[j*n+i]
+		}
+	}
+	corr := vek.MatMul(za, zbT, n)
+	vek.DivNumber_Inplace(corr, float64(n))
+	return fromFloat64([]int{p, q}, corr, promoteDType(a, b)), nil
+}
+
+// OutOfRangePolicy controls how HistogramAccumulator handles values outside its edges.
+type OutOfRangePolicy int
+
+const (
+	// DropOutOfRange ignores values outside the edges.
+	DropOutOfRange OutOfRangePolicy = iota
+	// ClampOutOfRange counts values below the first edge in the first bin and
+	// values above the last edge in the last bin.
+	ClampOutOfRange
+)
+
+// HistogramAccumulator builds a histogram with fixed bin edges incrementally
+// over streamed batches without retaining the data. Bins are half-open
+// [edges[i], edges[i+1]) except the last, which includes its upper edge.
+// NaN values are always dropped.
+type HistogramAccumulator struct {
+	edges  []float64
+	counts []float64
+	policy OutOfRangePolicy
+}
+
+// NewHistogramAccumulator creates an accumulator over the given strictly
+// increasing bin edges (at least two).
+func NewHistogramAccumulator(edges []float64, policy OutOfRangePolicy) (*HistogramAccumulator, error) {
+	if len(edges) < 2 {
+		return nil, errors.New("histogram requires at least two edges")
+	}
+	for i := 1; i < len(edges); i++ {
+		if !(edges[i] > edges[i-1]) {
+			return nil, errors.New("histogram edges must be strictly increasing")
+		}
+	}
+	if policy != DropOutOfRange && policy != ClampOutOfRange {
+		return nil, fmt.Errorf("invalid out-of-range policy %d", policy)
+	}
+	return &HistogramAccumulator{
+		edges:  slices.Clone(edges),
+		counts: make([]float64, len(edges)-1),
+		policy: policy,
+	}, nil
+}
+
+// Update adds every element of a to the histogram. a must be numeric.
+func (h *HistogramAccumulator) Update(a *NdArray) error {
+	data, err := a.toFloat64()
+	if err != nil {
+		return err
+	}
+	lo, hi := h.edges[0], h.edges[len(h.edges)-1]
+	last := len(h.counts) - 1
+	for _, v := range data {
+		switch {
+		case math.IsNaN(v):
+			continue
+		case v < lo || v > hi:
+			if h.policy == DropOutOfRange {
+				continue
+			}
+			if v < lo {
+				h.counts[0]++
+			} else {
+				h.counts[last]++
+			}
+		default:
+			// Index of the first edge greater than v, minus one, is v's bin.
+			bin, found := slices.BinarySearch(h.edges, v)
+			if !found {
+				bin--
+			}
+			h.counts[min(bin, last)]++
+		}
+	}
+	return nil
+}
+
+// Counts returns a copy of the current bin counts as a 1-D Float64 array.
+func (h *HistogramAccumulator) Counts() *NdArray {
+	return &NdArray{shape: []int{len(h.counts)}, data: slices.Clone(h.counts), dtype: Float64}
+}
+
+// Edges returns a copy of the bin edges.
+func (h *HistogramAccumulator) Edges() []float64 {
+	return slices.Clone(h.edges)
+}
+
+// QuantileEstimator approximates quantiles of an unbounded stream of scalars
+// in bounded memory using a merging t-digest. Samples are summarized by at
+// most O(compression) weighted centroids, sized by the arcsine scale function
+// so that centroids near the tails hold very few points. As a result the
+// error of Quantile(q) is roughly proportional to q(1-q)/compression: extreme
+// quantiles such as 0.001 or 0.999 are typically accurate to a few parts per
+// million of rank, while the median is accurate to within about 1/compression
+// of rank. These are empirical bounds rather than hard guarantees. The minimum
+// and maximum are tracked exactly.
+type QuantileEstimator struct {
+	compression float64
+	centroids   []centroid
+	buffer      []centroid
+	count       float64
+	min, max    float64
+}
+
+type centroid struct {
+	mean, weight float64
+}
+
+// NewQuantileEstimator creates an estimator with the given compression
+// (typically 100; larger is more accurate and uses more memory).
+func NewQuantileEstimator(compression float64) (*QuantileEstimator, error) {
+	if !(compression >= 10) {
+		return nil, fmt.Errorf("compression must be at least 10, got %g", compression)
+	}
+	return &QuantileEstimator{compression: compression, min: math.Inf(1), max: math.Inf(-1)}, nil
+}
+
+// Update adds a sample to the estimator. NaN samples are ignored.
+func (e *QuantileEstimator) Update(x float64) {
+	if math.IsNaN(x) {
+		return
+	}
+	e.add(centroid{mean: x, weight: 1})
+	e.min = min(e.min, x)
+	e.max = max(e.max, x)
+}
+
+func (e *QuantileEstimator) add(c centroid) {
+	e.buffer = append(e.buffer, c)
+	e.count += c.weight
+	if float64(len(e.buffer)) >= 5*e.compression {
+		e.compress()
+	}
+}
+
+// Count returns the number of samples seen.
+func (e *QuantileEstimator) Count() int {
+	return int(e.count)
+}
+
+// Merge folds the samples summarized by other into e, e.g. to combine
+// estimators built by parallel workers. other is left unchanged.
+func (e *QuantileEstimator) Merge(other *QuantileEstimator) {
+	for _, c := range other.centroids {
+		e.add(c)
+	}
+	for _, c := range other.buffer {
+		e.add(c)
+	}
+	e.min = min(e.min, other.min)
+	e.max = max(e.max, other.max)
+}
+
+// compress merges buffered samples into the centroid list, combining
+// neighbours while each centroid stays within one unit of the scale function.
+func (e *QuantileEstimator) compress() {
+	if len(e.buffer) == 0 {
+		return
+	}
+	all := append(e.centroids, e.buffer...)
+	slices.SortFunc(all, func(x, y centroid) int { return cmp.Compare(x.mean, y.mean) })
+
+	k := func(q float64) float64 { return e.compression / (2 * math.Pi) * math.Asin(2*q-1) }
+	kInv := func(k float64) float64 { return (math.Sin(k*2*math.Pi/e.compression) + 1) / 2 }
+
+	merged := make([]centroid, 0, int(e.compression))
+	cur := all[0]
+	soFar := 0.0
+	limit := kInv(k(0)+1) * e.count
+	for _, c := range all[1:] {
+		if soFar+cur.weight+c.weight <= limit {
+			w := cur.weight + c.weight
+			cur.mean += (c.mean - cur.mean) * c.weight / w
+			cur.weight = w
+			continue
+		}
+		merged = append(merged, cur)
+		soFar += cur.weight
+		limit = kInv(k(soFar/e.count)+1) * e.count
+		cur = c
+	}
+	e.centroids = append(merged, cur)
+	e.buffer = e.buffer[:0]
+}
+
+// Quantile returns the estimated q-th quantile (0 <= q <= 1), or NaN if the
+// estimator is empty or q is out of range.
+func (e *QuantileEstimator) Quantile(q float64) float64 {
+	if e.count == 0 || !(q >= 0 && q <= 1) {
+		return math.NaN()
+	}
+	e.compress()
+	switch q {
+	case 0:
+		return e.min
+	case 1:
+		return e.max
+	}
+	cs := e.centroids
+	if len(cs) == 1 {
+		return cs[0].mean
+	}
+
+	target := q * e.count
+	// Interpolate between the exact minimum and the first centroid's center.
+	if target < cs[0].weight/2 {
+		return e.min + (cs[0].mean-e.min)*target/(cs[0].weight/2)
+	}
+	cum := 0.0
+	for i := 0; i < len(cs)-1; i++ {
+		left := cum + cs[i].weight/2
+		right := cum + cs[i].weight + cs[i+1].weight/2
+		if target < right {
+			frac := (target - left) / (right - left)
+			return cs[i].mean + (cs[i+1].mean-cs[i].mean)*frac
+		}
+		cum += cs[i].weight
+	}
+	// Interpolate between the last centroid's center and the exact maximum.
+	last := cs[len(cs)-1]
+	left := e.count - last.weight/2
+	return last.mean + (e.max-last.mean)*(target-left)/(last.weight/2)
+}
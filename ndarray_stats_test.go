@@ -0,0 +1,449 @@
+package ndvek
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestValueCounts(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 4}, []float64{3, 1, 2, 3, 1, 3, 5, 2})
+
+	values, counts, err := a.ValueCounts()
+	if err != nil {
+		t.Fatalf("ValueCounts: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(values.Float64Data(), []float64{3, 1, 2, 5}) {
+		t.Errorf("ValueCounts: expected values [3 1 2 5], got %v", values.Float64Data())
+	}
+	if !reflect.DeepEqual(counts.Float64Data(), []float64{3, 2, 2, 1}) {
+		t.Errorf("ValueCounts: expected counts [3 2 2 1], got %v", counts.Float64Data())
+	}
+
+	b, _ := NewNdArray([]int{3}, []float32{0.5, 0.5, 1})
+	values, _, _ = b.ValueCounts()
+	if values.DType() != Float32 || !reflect.DeepEqual(values.Float32Data(), []float32{0.5, 1}) {
+		t.Errorf("ValueCounts float32: expected Float32 [0.5 1], got %v", values)
+	}
+
+	ints, _ := NewNdArray([]int{3}, []int64{2, 7, 2})
+	values, _, _ = ints.ValueCounts()
+	if values.DType() != Float64 || !reflect.DeepEqual(values.Float64Data(), []float64{2, 7}) {
+		t.Errorf("ValueCounts int64: expected Float64 values [2 7], got %v", values)
+	}
+
+	mask, _ := NewNdArray([]int{2}, []bool{true, false})
+	if _, _, err := mask.ValueCounts(); err == nil {
+		t.Error("ValueCounts: expected error for Bool array")
+	}
+}
+
+func TestWeightedQuantile(t *testing.T) {
+	a, _ := NewNdArray([]int{4}, []float64{4, 1, 3, 2})
+	ones, _ := NewNdArray([]int{4}, []float64{1, 1, 1, 1})
+
+	got, err := a.WeightedQuantile(ones, 0.5)
+	if err != nil {
+		t.Fatalf("WeightedQuantile: unexpected error: %v", err)
+	}
+	if got != 2.5 {
+		t.Errorf("WeightedQuantile: expected median 2.5, got %v", got)
+	}
+	if got, _ := a.WeightedQuantile(ones, 0); got != 1 {
+		t.Errorf("WeightedQuantile: expected q=0 to give 1, got %v", got)
+	}
+	if got, _ := a.WeightedQuantile(ones, 1); got != 4 {
+		t.Errorf("WeightedQuantile: expected q=1 to give 4, got %v", got)
+	}
+
+	// Putting all the weight on one value makes it every quantile.
+	w, _ := NewNdArray([]int{4}, []float64{0, 0, 5, 0})
+	if got, _ := a.WeightedQuantile(w, 0.2); got != 3 {
+		t.Errorf("WeightedQuantile: expected 3, got %v", got)
+	}
+
+	// Sorted positions are 0.5, 1.5, 2.5, 4.5 out of 6; the median interpolates
+	// between 3 and 4.
+	w, _ = NewNdArray([]int{4}, []float64{3, 1, 1, 1})
+	if got, _ := a.WeightedQuantile(w, 0.5); got != 3.25 {
+		t.Errorf("WeightedQuantile: expected skewed median 3.25, got %v", got)
+	}
+
+	neg, _ := NewNdArray([]int{4}, []float64{1, -1, 1, 1})
+	if _, err := a.WeightedQuantile(neg, 0.5); err == nil {
+		t.Error("WeightedQuantile: expected error for negative weights")
+	}
+	zero := Zeros([]int{4})
+	if _, err := a.WeightedQuantile(zero, 0.5); err == nil {
+		t.Error("WeightedQuantile: expected error for zero total weight")
+	}
+	if _, err := a.WeightedQuantile(Ones([]int{2, 2}), 0.5); err == nil {
+		t.Error("WeightedQuantile: expected error for mismatched shapes")
+	}
+	if _, err := a.WeightedQuantile(ones, 1.5); err == nil {
+		t.Error("WeightedQuantile: expected error for q outside [0, 1]")
+	}
+}
+
+func TestAutocorr(t *testing.T) {
+	a, _ := NewNdArray([]int{4}, []float64{1, 2, 3, 4})
+
+	acf, err := a.Autocorr(2)
+	if err != nil {
+		t.Fatalf("Autocorr: unexpected error: %v", err)
+	}
+	// Centered [-1.5 -0.5 0.5 1.5], sum of squares 5
+	expected := []float64{1, 0.25, -0.3}
+	got := acf.Float64Data()
+	for i := range expected {
+		if math.Abs(got[i]-expected[i]) > 1e-12 {
+			t.Errorf("Autocorr: expected %v, got %v", expected, got)
+			break
+		}
+	}
+
+	if _, err := a.Autocorr(4); err == nil {
+		t.Error("Autocorr: expected error for maxLag >= length")
+	}
+	flat, _ := NewNdArray([]int{3}, []float64{2, 2, 2})
+	if _, err := flat.Autocorr(1); err == nil {
+		t.Error("Autocorr: expected error for a constant series")
+	}
+	m, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+	if _, err := m.Autocorr(1); err == nil {
+		t.Error("Autocorr: expected error for a 2-D array")
+	}
+}
+
+func TestEffectiveSampleSize(t *testing.T) {
+	// An alternating chain is anti-correlated: each lag pair sums to about
+	// +0.01, so tau collapses toward zero and the n*log10(n) cap applies.
+	alt := make([]float64, 100)
+	for i := range alt {
+		alt[i] = float64(i % 2)
+	}
+	a, _ := NewNdArray([]int{100}, alt)
+	ess, err := a.EffectiveSampleSize()
+	if err != nil {
+		t.Fatalf("EffectiveSampleSize: unexpected error: %v", err)
+	}
+	if math.Abs(ess-200) > 1e-9 {
+		t.Errorf("EffectiveSampleSize: expected the cap n*log10(n) = 200 for an anti-correlated chain, got %v", ess)
+	}
+
+	// A slowly varying chain is strongly autocorrelated.
+	slow := make([]float64, 200)
+	for i := range slow {
+		slow[i] = math.Sin(float64(i) / 20)
+	}
+	b, _ := NewNdArray([]int{200}, slow)
+	ess, _ = b.EffectiveSampleSize()
+	if ess <= 0 || ess > 20 {
+		t.Errorf("EffectiveSampleSize: expected a small ESS for a smooth chain, got %v", ess)
+	}
+}
+
+func TestHistogram2d(t *testing.T) {
+	x, _ := NewNdArray([]int{6}, []float64{0, 0.5, 1, 1.5, 2, 5})
+	y, _ := NewNdArray([]int{6}, []float32{0, 0.9, 1, 1.9, 2, 1})
+
+	counts, err := Histogram2d(x, y, 2, 2, [2]float64{0, 2}, [2]float64{0, 2})
+	if err != nil {
+		t.Fatalf("Histogram2d: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(counts.Shape(), []int{2, 2}) {
+		t.Errorf("Histogram2d: expected shape [2 2], got %v", counts.Shape())
+	}
+	// (0,0),(0.5,0.9) -> [0,0]; (1,1),(1.5,1.9),(2,2) -> [1,1]; x=5 dropped
+	expected := []float64{2, 0, 0, 3}
+	if !reflect.DeepEqual(counts.Float64Data(), expected) {
+		t.Errorf("Histogram2d: expected %v, got %v", expected, counts.Float64Data())
+	}
+
+	short, _ := NewNdArray([]int{2}, []float64{0, 1})
+	if _, err := Histogram2d(x, short, 2, 2, [2]float64{0, 2}, [2]float64{0, 2}); err == nil {
+		t.Error("Histogram2d: expected error for mismatched lengths")
+	}
+	if _, err := Histogram2d(x, y, 0, 2, [2]float64{0, 2}, [2]float64{0, 2}); err == nil {
+		t.Error("Histogram2d: expected error for non-positive bins")
+	}
+	if _, err := Histogram2d(x, y, 2, 2, [2]float64{2, 0}, [2]float64{0, 2}); err == nil {
+		t.Error("Histogram2d: expected error for inverted range")
+	}
+}
+
+func TestCrossCorr(t *testing.T) {
+	// Columns: x, 2x+1, constant
+	a, _ := NewNdArray([]int{4, 3}, []float64{
+		1, 3, 5,
+		2, 5, 5,
+		3, 7, 5,
+		4, 9, 5,
+	})
+	// Columns: -x, x^2
+	b, _ := NewNdArray([]int{4, 2}, []float64{
+		-1, 1,
+		-2, 4,
+		-3, 9,
+		-4, 16,
+	})
+
+	corr, err := CrossCorr(a, b)
+	if err != nil {
+		t.Fatalf("CrossCorr: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(corr.Shape(), []int{3, 2}) {
+		t.Fatalf("CrossCorr: expected shape [3 2], got %v", corr.Shape())
+	}
+	got := corr.Float64Data()
+	const eps = 1e-12
+	if math.Abs(got[0]+1) > eps || math.Abs(got[2]+1) > eps {
+		t.Errorf("CrossCorr: expected -1 for linear columns, got %v", got)
+	}
+	if got[1] < 0.98 || got[1] > 1 {
+		t.Errorf("CrossCorr: expected strong positive correlation with x^2, got %v", got[1])
+	}
+	if !math.IsNaN(got[4]) || !math.IsNaN(got[5]) {
+		t.Errorf("CrossCorr: expected NaN for zero-variance column, got %v", got[4:])
+	}
+
+	c, _ := NewNdArray([]int{3, 1}, []float64{1, 2, 3})
+	if _, err := CrossCorr(a, c); err == nil {
+		t.Error("CrossCorr: expected error for mismatched row counts")
+	}
+	none, _ := NewNdArray([]int{4, 0}, []float64{})
+	if res, err := CrossCorr(a, none); err != nil || !reflect.DeepEqual(res.Shape(), []int{3, 0}) {
+		t.Errorf("CrossCorr: expected an empty [3 0] result, got %v (err %v)", res, err)
+	}
+}
+
+func TestHistogramAccumulator(t *testing.T) {
+	h, err := NewHistogramAccumulator([]float64{0, 1, 2, 4}, DropOutOfRange)
+	if err != nil {
+		t.Fatalf("NewHistogramAccumulator: unexpected error: %v", err)
+	}
+
+	batch1, _ := NewNdArray([]int{4}, []float64{0, 0.5, 1, 4})
+	batch2, _ := NewNdArray([]int{2, 2}, []float32{3, -1, 2, 9})
+	if err := h.Update(batch1); err != nil {
+		t.Fatalf("Update: unexpected error: %v", err)
+	}
+	if err := h.Update(batch2); err != nil {
+		t.Fatalf("Update: unexpected error: %v", err)
+	}
+	nan, _ := NewNdArray([]int{1}, []float64{math.NaN()})
+	h.Update(nan)
+
+	expected := []float64{2, 1, 3}
+	if !reflect.DeepEqual(h.Counts().Float64Data(), expected) {
+		t.Errorf("Counts: expected %v, got %v", expected, h.Counts().Float64Data())
+	}
+
+	clamp, _ := NewHistogramAccumulator([]float64{0, 1, 2, 4}, ClampOutOfRange)
+	clamp.Update(batch2)
+	expected = []float64{1, 0, 3}
+	if !reflect.DeepEqual(clamp.Counts().Float64Data(), expected) {
+		t.Errorf("Counts clamped: expected %v, got %v", expected, clamp.Counts().Float64Data())
+	}
+
+	mask, _ := NewNdArray([]int{1}, []bool{true})
+	if err := h.Update(mask); err == nil {
+		t.Error("Update: expected error for Bool array")
+	}
+	if _, err := NewHistogramAccumulator([]float64{0, 0, 1}, DropOutOfRange); err == nil {
+		t.Error("NewHistogramAccumulator: expected error for non-increasing edges")
+	}
+	if _, err := NewHistogramAccumulator([]float64{1}, DropOutOfRange); err == nil {
+		t.Error("NewHistogramAccumulator: expected error for a single edge")
+	}
+}
+
+func TestQuantileEstimator(t *testing.T) {
+	e, err := NewQuantileEstimator(100)
+	if err != nil {
+		t.Fatalf("NewQuantileEstimator: unexpected error: %v", err)
+	}
+	if !math.IsNaN(e.Quantile(0.5)) {
+		t.Error("Quantile: expected NaN for empty estimator")
+	}
+
+	// A deterministic permutation of 0..n-1 so the stream isn't sorted
+	const n = 100000
+	for i := range n {
+		e.Update(float64((i * 7919) % n))
+	}
+	if e.Count() != n {
+		t.Errorf("Count: expected %d, got %d", n, e.Count())
+	}
+
+	tests := []struct{ q, tol float64 }{
+		{0.5, 0.01}, {0.9, 0.005}, {0.99, 0.001}, {0.999, 0.0005},
+	}
+	for _, tt := range tests {
+		got := e.Quantile(tt.q) / n
+		if math.Abs(got-tt.q) > tt.tol {
+			t.Errorf("Quantile(%v): expected within %v of %v, got %v", tt.q, tt.tol, tt.q, got)
+		}
+	}
+	if e.Quantile(0) != 0 || e.Quantile(1) != n-1 {
+		t.Errorf("Quantile: expected exact extremes 0 and %d, got %v and %v", n-1, e.Quantile(0), e.Quantile(1))
+	}
+
+	// Merging two halves should match the estimator over the whole stream
+	lo, _ := NewQuantileEstimator(100)
+	hi, _ := NewQuantileEstimator(100)
+	for i := range n / 2 {
+		lo.Update(float64(i))
+		hi.Update(float64(i + n/2))
+	}
+	lo.Merge(hi)
+	if lo.Count() != n {
+		t.Errorf("Merge: expected count %d, got %d", n, lo.Count())
+	}
+	if got := lo.Quantile(0.99) / n; math.Abs(got-0.99) > 0.001 {
+		t.Errorf("Merge: expected Quantile(0.99) near 0.99, got %v", got)
+	}
+
+	if _, err := NewQuantileEstimator(1); err == nil {
+		t.Error("NewQuantileEstimator: expected error for tiny compression")
+	}
+}
+
+func TestThinBurn(t *testing.T) {
+	a, _ := NewNdArray([]int{5, 2}, []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+
+	thin, err := a.Thin(2, 0)
+	if err != nil {
+		t.Fatalf("Thin: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(thin.Shape(), []int{3, 2}) || !reflect.DeepEqual(thin.Float64Data(), []float64{0, 1, 4, 5, 8, 9}) {
+		t.Errorf("Thin: expected [3 2] [0 1 4 5 8 9], got %v %v", thin.Shape(), thin.Float64Data())
+	}
+	cols, _ := a.Thin(2, -1)
+	if !reflect.DeepEqual(cols.Float64Data(), []float64{0, 2, 4, 6, 8}) {
+		t.Errorf("Thin: expected [0 2 4 6 8] along last axis, got %v", cols.Float64Data())
+	}
+
+	burned, err := a.Burn(3, 0)
+	if err != nil {
+		t.Fatalf("Burn: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(burned.Shape(), []int{2, 2}) || !reflect.DeepEqual(burned.Float64Data(), []float64{6, 7, 8, 9}) {
+		t.Errorf("Burn: expected [2 2] [6 7 8 9], got %v %v", burned.Shape(), burned.Float64Data())
+	}
+	burned.Float64Data()[0] = -1
+	if a.Float64Data()[6] != 6 {
+		t.Error("Burn: result should not share the input buffer")
+	}
+
+	if _, err := a.Thin(0, 0); err == nil {
+		t.Error("Thin: expected error for non-positive step")
+	}
+	if _, err := a.Burn(5, 0); err == nil {
+		t.Error("Burn: expected error when burning every draw")
+	}
+	if _, err := a.Burn(1, 2); err == nil {
+		t.Error("Burn: expected error for out-of-range axis")
+	}
+}
+
+func TestRHat(t *testing.T) {
+	// Two chains, two parameters. Parameter 0 has identical chain means;
+	// parameter 1 has chains stuck in different places.
+	c1, _ := NewNdArray([]int{4, 2}, []float64{1, 0, 2, 1, 3, 0, 4, 1})
+	c2, _ := NewNdArray([]int{4, 2}, []float64{4, 10, 3, 11, 2, 10, 1, 11})
+
+	r, err := RHat([]*NdArray{c1, c2})
+	if err != nil {
+		t.Fatalf("RHat: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(r.Shape(), []int{2}) {
+		t.Fatalf("RHat: expected shape [2], got %v", r.Shape())
+	}
+	// W = 5/3, B/n = 0: sqrt((3/4)*(5/3) / (5/3)) = sqrt(3/4).
+	if got := r.Float64Data()[0]; math.Abs(got-math.Sqrt(0.75)) > 1e-12 {
+		t.Errorf("RHat: expected %v for mixed parameter, got %v", math.Sqrt(0.75), got)
+	}
+	// W = 1/3, B/n = 50: sqrt((1/4 + 50) / (1/3)).
+	if got, want := r.Float64Data()[1], math.Sqrt((0.25+50)*3); math.Abs(got-want) > 1e-12 {
+		t.Errorf("RHat: expected %v for unmixed parameter, got %v", want, got)
+	}
+
+	short, _ := NewNdArray([]int{1, 2}, []float64{1, 2})
+	other, _ := NewNdArray([]int{3, 2}, make([]float64, 6))
+	if _, err := RHat([]*NdArray{c1}); err == nil {
+		t.Error("RHat: expected error for a single chain")
+	}
+	if _, err := RHat([]*NdArray{c1, other}); err == nil {
+		t.Error("RHat: expected error for mismatched chain shapes")
+	}
+	if _, err := RHat([]*NdArray{short, short}); err == nil {
+		t.Error("RHat: expected error for fewer than 2 draws")
+	}
+
+	flat, _ := NewNdArray([]int{3, 1}, []float64{5, 5, 5})
+	if r, err := RHat([]*NdArray{flat, flat}); err != nil || r.Float64Data()[0] != 1 {
+		t.Errorf("RHat: expected 1 for identical constant chains, got %v (err %v)", r, err)
+	}
+	shifted, _ := NewNdArray([]int{3, 1}, []float64{6, 6, 6})
+	if _, err := RHat([]*NdArray{flat, shifted}); err == nil {
+		t.Error("RHat: expected error for constant chains at different values")
+	}
+}
+
+func TestCredibleInterval(t *testing.T) {
+	// Two parameters of five draws each, draws along axis 0.
+	a, _ := NewNdArray([]int{5, 2}, []float64{1, 0, 2, 1, 3, 2, 4, 3, 5, 100})
+
+	lo, hi, err := a.CredibleInterval(0.5, 0)
+	if err != nil {
+		t.Fatalf("CredibleInterval: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(lo.Float64Data(), []float64{2, 1}) || !reflect.DeepEqual(hi.Float64Data(), []float64{4, 3}) {
+		t.Errorf("CredibleInterval: expected [2 1] to [4 3], got %v to %v", lo.Float64Data(), hi.Float64Data())
+	}
+
+	lo, hi, err = a.HPDInterval(0.5, 0)
+	if err != nil {
+		t.Fatalf("HPDInterval: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(lo.Float64Data(), []float64{1, 0}) || !reflect.DeepEqual(hi.Float64Data(), []float64{3, 2}) {
+		t.Errorf("HPDInterval: expected [1 0] to [3 2], got %v to %v", lo.Float64Data(), hi.Float64Data())
+	}
+	if a.Float64Data()[8] != 5 {
+		t.Error("HPDInterval: input was reordered")
+	}
+
+	if _, _, err := a.CredibleInterval(1, 0); err == nil {
+		t.Error("CredibleInterval: expected error for prob outside (0, 1)")
+	}
+	if _, _, err := a.HPDInterval(0.9, 2); err == nil {
+		t.Error("HPDInterval: expected error for out-of-range axis")
+	}
+}
+
+func TestConstantColumns(t *testing.T) {
+	nan := math.NaN()
+	a, _ := NewNdArray([]int{3, 4}, []float64{
+		1, 5, 2, nan,
+		1, 6, 2.05, nan,
+		1, 7, 2.1, nan,
+	})
+
+	cols, err := a.ConstantColumns(0)
+	if err != nil {
+		t.Fatalf("ConstantColumns: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cols, []int{0}) {
+		t.Errorf("ConstantColumns: expected [0], got %v", cols)
+	}
+	cols, _ = a.ConstantColumns(0.2)
+	if !reflect.DeepEqual(cols, []int{0, 2}) {
+		t.Errorf("ConstantColumns: expected [0 2] with tolerance, got %v", cols)
+	}
+
+	flat, _ := NewNdArray([]int{4}, []float64{1, 1, 1, 1})
+	if _, err := flat.ConstantColumns(0); err == nil {
+		t.Error("ConstantColumns: expected error for 1-D input")
+	}
+}
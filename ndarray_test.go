@@ -1,8 +1,10 @@
 package ndvek
 
 import (
+	"fmt"
 	"math"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -55,6 +57,98 @@ func TestNewNdArray(t *testing.T) {
 	}
 }
 
+func TestInt64DType(t *testing.T) {
+	a, err := NewNdArray([]int{2, 3}, []int64{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Fatalf("NewNdArray: %v", err)
+	}
+	if a.DType() != Int64 {
+		t.Fatalf("DType: expected Int64, got %v", a.DType())
+	}
+	if v, _ := a.Get([]int{1, 2}); v != 6 {
+		t.Errorf("Get: expected 6, got %v", v)
+	}
+	if s := a.Sum(); s != 21 {
+		t.Errorf("Sum: expected 21, got %v", s)
+	}
+
+	r, err := a.Reshape([]int{3, -1})
+	if err != nil {
+		t.Fatalf("Reshape: %v", err)
+	}
+	if !reflect.DeepEqual(r.Shape(), []int{3, 2}) || r.DType() != Int64 {
+		t.Errorf("Reshape: expected Int64 [3 2], got %v %v", r.DType(), r.Shape())
+	}
+
+	row, _ := NewNdArray([]int{3}, []int64{10, 20, 30})
+	sum, err := Add(a, row)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if sum.DType() != Int64 || !reflect.DeepEqual(sum.Int64Data(), []int64{11, 22, 33, 14, 25, 36}) {
+		t.Errorf("Add: expected Int64 [11 22 33 14 25 36], got %v %v", sum.DType(), sum.data)
+	}
+
+	// Exact accumulation beyond float64's 2^53 integer range.
+	big, _ := NewNdArray([]int{1}, []int64{1 << 60})
+	one, _ := NewNdArray([]int{1}, []int64{1})
+	bigSum, _ := Add(big, one)
+	if got := bigSum.Int64Data()[0]; got != 1<<60+1 {
+		t.Errorf("Add: expected exact %d, got %d", int64(1<<60+1), got)
+	}
+
+	half, _ := NewNdArray([]int{1}, []float64{0.5})
+	mixed, err := Multiply(a, half)
+	if err != nil {
+		t.Fatalf("Multiply: %v", err)
+	}
+	if mixed.DType() != Float64 || !reflect.DeepEqual(mixed.Float64Data(), []float64{0.5, 1, 1.5, 2, 2.5, 3}) {
+		t.Errorf("Multiply: expected Float64 halves, got %v %v", mixed.DType(), mixed.data)
+	}
+
+	quot, _ := Divide(row, one)
+	if quot.DType() != Float64 {
+		t.Errorf("Divide: expected Float64 result, got %v", quot.DType())
+	}
+
+	if err := a.ApplyOpInPlace(row, func(x, y float64) float64 { return x + y }); err == nil {
+		t.Error("ApplyOpInPlace: expected error for Int64 receiver")
+	}
+}
+
+func TestMeta(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []float32{1, 2, 3, 4, 5, 6})
+
+	shape, dtype, size := a.Meta()
+	if !reflect.DeepEqual(shape, []int{2, 3}) || dtype != Float32 || size != 6 {
+		t.Errorf("Meta: expected ([2 3], Float32, 6), got (%v, %v, %d)", shape, dtype, size)
+	}
+
+	shape[0] = 99
+	s := a.Shape()
+	s[1] = 99
+	if !reflect.DeepEqual(a.Shape(), []int{2, 3}) {
+		t.Errorf("Shape: mutating returned slices should not affect the array, got %v", a.Shape())
+	}
+}
+
+func TestAssertShape(t *testing.T) {
+	a := Zeros([]int{4, 3, 2})
+
+	if err := a.AssertShape(4, 3, 2); err != nil {
+		t.Errorf("AssertShape: unexpected error: %v", err)
+	}
+	if err := a.AssertShape(-1, 3, -1); err != nil {
+		t.Errorf("AssertShape with wildcards: unexpected error: %v", err)
+	}
+	if err := a.AssertShape(4, 2, 2); err == nil {
+		t.Error("AssertShape: expected error for mismatched dimension")
+	}
+	if err := a.AssertShape(4, 3); err == nil {
+		t.Error("AssertShape: expected error for mismatched rank")
+	}
+}
+
 func TestBroadcastShapes(t *testing.T) {
 	tests := []struct {
 		shape1, shape2, expected []int
@@ -84,6 +178,37 @@ func TestBroadcastShapes(t *testing.T) {
 	}
 }
 
+func TestBroadcastIndices(t *testing.T) {
+	idx1, idx2, outShape, err := BroadcastIndices([]int{2, 1}, []int{3})
+	if err != nil {
+		t.Fatalf("BroadcastIndices: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(outShape, []int{2, 3}) {
+		t.Errorf("BroadcastIndices: expected shape [2 3], got %v", outShape)
+	}
+	if expected := []int{0, 0, 0, 1, 1, 1}; !reflect.DeepEqual(idx1, expected) {
+		t.Errorf("BroadcastIndices: expected idx1 %v, got %v", expected, idx1)
+	}
+	if expected := []int{0, 1, 2, 0, 1, 2}; !reflect.DeepEqual(idx2, expected) {
+		t.Errorf("BroadcastIndices: expected idx2 %v, got %v", expected, idx2)
+	}
+
+	// Agrees with the per-element broadcastIndex on a rank-mismatched case
+	s1, s2 := []int{4, 1, 3}, []int{2, 1}
+	idx1, idx2, outShape, _ = BroadcastIndices(s1, s2)
+	for i := range idx1 {
+		want1, _ := broadcastIndex(s1, outShape, i)
+		want2, _ := broadcastIndex(s2, outShape, i)
+		if idx1[i] != want1 || idx2[i] != want2 {
+			t.Fatalf("BroadcastIndices: element %d expected (%d, %d), got (%d, %d)", i, want1, want2, idx1[i], idx2[i])
+		}
+	}
+
+	if _, _, _, err := BroadcastIndices([]int{2, 3}, []int{4, 3}); err == nil {
+		t.Error("BroadcastIndices: expected error for incompatible shapes")
+	}
+}
+
 func TestArithmeticOperations(t *testing.T) {
 	a, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 3, 4, 5, 6})
 	b, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
@@ -190,6 +315,232 @@ func TestScalarArithmetic(t *testing.T) {
 	}
 }
 
+func TestFloat32Preservation(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float32{1, 2, 3, 4})
+	row, _ := NewNdArray([]int{2}, []float32{1, 2})
+	s := NewScalar32(2)
+
+	ops := map[string]func(x, y *NdArray) (*NdArray, error){
+		"Add": Add, "Subtract": Subtract, "Multiply": Multiply, "Divide": Divide,
+	}
+	for name, op := range ops {
+		for _, b := range []*NdArray{s, row} {
+			res, err := op(a, b)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, err)
+			}
+			if res.DType() != Float32 {
+				t.Errorf("%s with shape %v: expected Float32, got %v", name, b.Shape(), res.DType())
+			}
+			res, _ = op(b, a)
+			if res.DType() != Float32 {
+				t.Errorf("%s reversed with shape %v: expected Float32, got %v", name, b.Shape(), res.DType())
+			}
+		}
+	}
+
+	res, _ := Subtract(a, row)
+	if !reflect.DeepEqual(res.Float32Data(), []float32{0, 0, 2, 2}) {
+		t.Errorf("Subtract broadcast float32: expected [0 0 2 2], got %v", res.Float32Data())
+	}
+
+	// Mixing with a Float64 scalar widens
+	res, _ = Add(a, NewScalar(1))
+	if res.DType() != Float64 {
+		t.Errorf("Add with NewScalar: expected Float64, got %v", res.DType())
+	}
+}
+
+func TestDivideSafe(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+	b, _ := NewNdArray([]int{2}, []float64{2, 0})
+
+	res, err := DivideSafe(a, b, -1)
+	if err != nil {
+		t.Fatalf("DivideSafe: unexpected error: %v", err)
+	}
+	expected := []float64{0.5, -1, 1.5, -1}
+	if !reflect.DeepEqual(res.Float64Data(), expected) {
+		t.Errorf("DivideSafe: expected %v, got %v", expected, res.Float64Data())
+	}
+
+	c, _ := NewNdArray([]int{2}, []float32{1, 1})
+	d, _ := NewNdArray([]int{2}, []float32{0, 4})
+	res, _ = DivideSafe(c, d, 0)
+	if res.DType() != Float32 {
+		t.Errorf("DivideSafe: expected Float32 dtype, got %v", res.DType())
+	}
+	if !reflect.DeepEqual(res.Float32Data(), []float32{0, 0.25}) {
+		t.Errorf("DivideSafe float32: expected [0 0.25], got %v", res.Float32Data())
+	}
+
+	e, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+	if _, err := DivideSafe(a, e, 0); err == nil {
+		t.Error("DivideSafe: expected error for incompatible shapes")
+	}
+}
+
+func TestWeightedSum(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+	row, _ := NewNdArray([]int{2}, []float64{10, 20})
+	scalar := NewScalar(100)
+
+	res, err := WeightedSum([]*NdArray{a, row, scalar}, []float64{2, 0.5, -1})
+	if err != nil {
+		t.Fatalf("WeightedSum: unexpected error: %v", err)
+	}
+	expected := []float64{-93, -86, -89, -82}
+	if !reflect.DeepEqual(res.Shape(), []int{2, 2}) || !reflect.DeepEqual(res.Float64Data(), expected) {
+		t.Errorf("WeightedSum: expected [2 2] %v, got %v %v", expected, res.Shape(), res.Float64Data())
+	}
+
+	x, _ := NewNdArray([]int{2}, []float32{1, 2})
+	y, _ := NewNdArray([]int{2}, []float32{3, 4})
+	res32, _ := WeightedSum([]*NdArray{x, y}, []float64{1, 1})
+	if res32.DType() != Float32 || !reflect.DeepEqual(res32.Float32Data(), []float32{4, 6}) {
+		t.Errorf("WeightedSum: expected Float32 [4 6], got %v %v", res32.DType(), res32.data)
+	}
+
+	if _, err := WeightedSum([]*NdArray{a, row}, []float64{1}); err == nil {
+		t.Error("WeightedSum: expected error for weight count mismatch")
+	}
+	odd, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+	if _, err := WeightedSum([]*NdArray{a, odd}, []float64{1, 1}); err == nil {
+		t.Error("WeightedSum: expected error for incompatible shapes")
+	}
+}
+
+func TestLerp(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float64{0, 0, 10, 10})
+	b, _ := NewNdArray([]int{2, 2}, []float64{10, 20, 20, 30})
+	w, _ := NewNdArray([]int{1, 2}, []float64{0.5, 0.25})
+
+	res, err := Lerp(a, b, w)
+	if err != nil {
+		t.Fatalf("Lerp: unexpected error: %v", err)
+	}
+	expected := []float64{5, 5, 15, 15}
+	if !reflect.DeepEqual(res.Float64Data(), expected) {
+		t.Errorf("Lerp: expected %v, got %v", expected, res.Float64Data())
+	}
+
+	res, _ = Lerp(a, b, NewScalar(1))
+	if !reflect.DeepEqual(res.Float64Data(), b.Float64Data()) {
+		t.Errorf("Lerp: expected t=1 to return b, got %v", res.Float64Data())
+	}
+
+	c, _ := NewNdArray([]int{2}, []float32{0, 4})
+	d, _ := NewNdArray([]int{2}, []float32{2, 8})
+	res, _ = Lerp(c, d, NewScalar32(0.5))
+	if res.DType() != Float32 || !reflect.DeepEqual(res.Float32Data(), []float32{1, 6}) {
+		t.Errorf("Lerp float32: expected Float32 [1 6], got %v %v", res.DType(), res)
+	}
+	res, _ = Lerp(c, d, NewScalar(0.5))
+	if res.DType() != Float64 {
+		t.Errorf("Lerp: expected Float64 for mixed dtypes, got %v", res.DType())
+	}
+
+	e, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+	if _, err := Lerp(a, b, e); err == nil {
+		t.Error("Lerp: expected error for incompatible shapes")
+	}
+}
+
+func TestDivMod(t *testing.T) {
+	a, _ := NewNdArray([]int{4}, []float64{7, -7, 7, -7})
+	b, _ := NewNdArray([]int{4}, []float64{2, 2, -2, -2})
+
+	quot, rem, err := DivMod(a, b)
+	if err != nil {
+		t.Fatalf("DivMod: unexpected error: %v", err)
+	}
+	if expected := []float64{3, -4, -4, 3}; !reflect.DeepEqual(quot.Float64Data(), expected) {
+		t.Errorf("DivMod quotient: expected %v, got %v", expected, quot.Float64Data())
+	}
+	if expected := []float64{1, 1, -1, -1}; !reflect.DeepEqual(rem.Float64Data(), expected) {
+		t.Errorf("DivMod remainder: expected %v, got %v", expected, rem.Float64Data())
+	}
+
+	// Decompose flat indices into [rows, 3] grid coordinates
+	idx, _ := NewNdArray([]int{2, 2}, []float32{0, 4, 5, 11})
+	row, col, _ := DivMod(idx, NewScalar32(3))
+	if row.DType() != Float32 || !reflect.DeepEqual(row.Float32Data(), []float32{0, 1, 1, 3}) {
+		t.Errorf("DivMod float32 quotient: expected [0 1 1 3], got %v", row)
+	}
+	if !reflect.DeepEqual(col.Float32Data(), []float32{0, 1, 2, 2}) {
+		t.Errorf("DivMod float32 remainder: expected [0 1 2 2], got %v", col)
+	}
+
+	_, rem, _ = DivMod(a, NewScalar(0))
+	if !math.IsNaN(rem.Float64Data()[0]) {
+		t.Errorf("DivMod: expected NaN remainder for zero divisor, got %v", rem.Float64Data()[0])
+	}
+
+	c, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+	if _, _, err := DivMod(a, c); err == nil {
+		t.Error("DivMod: expected error for incompatible shapes")
+	}
+}
+
+func TestModPyMod(t *testing.T) {
+	a, _ := NewNdArray([]int{4}, []float64{7, -7, 7, -7})
+	b, _ := NewNdArray([]int{4}, []float64{3, 3, -3, -3})
+
+	mod, err := Mod(a, b)
+	if err != nil {
+		t.Fatalf("Mod: unexpected error: %v", err)
+	}
+	if expected := []float64{1, -1, 1, -1}; !reflect.DeepEqual(mod.Float64Data(), expected) {
+		t.Errorf("Mod: expected %v, got %v", expected, mod.Float64Data())
+	}
+
+	py, err := PyMod(a, b)
+	if err != nil {
+		t.Fatalf("PyMod: unexpected error: %v", err)
+	}
+	if expected := []float64{1, 2, -2, -1}; !reflect.DeepEqual(py.Float64Data(), expected) {
+		t.Errorf("PyMod: expected %v, got %v", expected, py.Float64Data())
+	}
+
+	_, rem, _ := DivMod(a, b)
+	if !reflect.DeepEqual(rem.Float64Data(), py.Float64Data()) {
+		t.Errorf("PyMod: expected to match the DivMod remainder %v, got %v", rem.Float64Data(), py.Float64Data())
+	}
+
+	c, _ := NewNdArray([]int{3}, []float32{-5.5, 5.5, -1})
+	py, _ = PyMod(c, NewScalar32(2))
+	if py.DType() != Float32 || !reflect.DeepEqual(py.Float32Data(), []float32{0.5, 1.5, 1}) {
+		t.Errorf("PyMod float32: expected Float32 [0.5 1.5 1], got %v", py)
+	}
+}
+
+func TestLogAddExp(t *testing.T) {
+	a, _ := NewNdArray([]int{3}, []float64{0, 1000, math.Inf(-1)})
+	b, _ := NewNdArray([]int{3}, []float64{0, 1000, 2})
+
+	res, err := LogAddExp(a, b)
+	if err != nil {
+		t.Fatalf("LogAddExp: unexpected error: %v", err)
+	}
+	got := res.Float64Data()
+	if math.Abs(got[0]-math.Ln2) > 1e-12 || math.Abs(got[1]-(1000+math.Ln2)) > 1e-9 || got[2] != 2 {
+		t.Errorf("LogAddExp: expected [ln2 1000+ln2 2], got %v", got)
+	}
+
+	inf, _ := NewNdArray([]int{2}, []float64{math.Inf(-1), math.Inf(1)})
+	res, _ = LogAddExp(inf, inf)
+	if got := res.Float64Data(); !math.IsInf(got[0], -1) || !math.IsInf(got[1], 1) {
+		t.Errorf("LogAddExp: expected [-Inf +Inf], got %v", got)
+	}
+
+	c, _ := NewNdArray([]int{2, 1}, []float32{0, 1})
+	d, _ := NewNdArray([]int{2}, []float32{0, 1})
+	res, _ = LogAddExp(c, d)
+	if res.DType() != Float32 || !reflect.DeepEqual(res.Shape(), []int{2, 2}) {
+		t.Errorf("LogAddExp: expected Float32 [2 2] result, got %v %v", res.DType(), res.Shape())
+	}
+}
+
 func TestVekExtensions(t *testing.T) {
 	a, _ := NewNdArray([]int{2, 2}, []float64{1, -2, 3, -4})
 
@@ -271,6 +622,148 @@ func TestVekExtensions(t *testing.T) {
 	}
 }
 
+func TestFloat32SumPrecision(t *testing.T) {
+	const n = 1 << 20
+	f32 := make([]float32, n)
+	ref := 0.0
+	for i := range f32 {
+		f32[i] = 0.1 + float32(i%7)*0.01
+		ref += float64(f32[i])
+	}
+	a, _ := NewNdArray([]int{n}, f32)
+
+	if got := a.Sum(); math.Abs(got-ref)/ref > 1e-9 {
+		t.Errorf("Sum: expected %v, got %v (relative error %g)", ref, got, math.Abs(got-ref)/ref)
+	}
+	if got := a.SumFast(); math.Abs(got-ref)/ref > 1e-3 {
+		t.Errorf("SumFast: expected approximately %v, got %v", ref, got)
+	}
+}
+
+func TestVarStd(t *testing.T) {
+	a, _ := NewNdArray([]int{8}, []float64{2, 4, 4, 4, 5, 5, 7, 9})
+	if v := a.Var(0); v != 4 {
+		t.Errorf("Var(0): expected 4, got %v", v)
+	}
+	if s := a.Std(0); s != 2 {
+		t.Errorf("Std(0): expected 2, got %v", s)
+	}
+	if v := a.Var(1); v != 32.0/7 {
+		t.Errorf("Var(1): expected %v, got %v", 32.0/7, v)
+	}
+
+	b, _ := NewNdArray([]int{2}, []float32{1, 3})
+	if v := b.Var(1); v != 2 {
+		t.Errorf("Var float32: expected 2, got %v", v)
+	}
+	one := NewScalar(5)
+	if v := one.Var(1); !math.IsNaN(v) {
+		t.Errorf("Var: expected NaN for a single element with ddof=1, got %v", v)
+	}
+}
+
+func TestSumAndSumSq(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float64{1, -2, 3, 4})
+	sum, sumSq := a.SumAndSumSq()
+	if sum != 6 || sumSq != 30 {
+		t.Errorf("SumAndSumSq: expected (6, 30), got (%v, %v)", sum, sumSq)
+	}
+
+	b, _ := NewNdArray([]int{3}, []float32{0.5, 1.5, 2})
+	sum, sumSq = b.SumAndSumSq()
+	if sum != 4 || sumSq != 6.5 {
+		t.Errorf("SumAndSumSq float32: expected (4, 6.5), got (%v, %v)", sum, sumSq)
+	}
+}
+
+func BenchmarkSumFloat32(b *testing.B) {
+	data, _ := Ones([]int{1 << 20}).toFloat32()
+	f32, _ := NewNdArray([]int{len(data)}, data)
+	b.Run("Sum", func(b *testing.B) {
+		for b.Loop() {
+			f32.Sum()
+		}
+	})
+	b.Run("SumFast", func(b *testing.B) {
+		for b.Loop() {
+			f32.SumFast()
+		}
+	})
+}
+
+func TestWhere(t *testing.T) {
+	cond, _ := NewNdArray([]int{2, 1}, []bool{true, false})
+	a, _ := NewNdArray([]int{3}, []float32{1, 2, 3})
+	b := NewScalar32(-1)
+
+	res, err := Where(cond, a, b)
+	if err != nil {
+		t.Fatalf("Where: unexpected error: %v", err)
+	}
+	if res.DType() != Float32 || !reflect.DeepEqual(res.Shape(), []int{2, 3}) ||
+		!reflect.DeepEqual(res.Float32Data(), []float32{1, 2, 3, -1, -1, -1}) {
+		t.Errorf("Where: expected Float32 [2 3] [1 2 3 -1 -1 -1], got %v %v %v", res.DType(), res.Shape(), res.data)
+	}
+
+	mask, _ := NewNdArray([]int{3}, []bool{false, true, false})
+	ints, _ := NewNdArray([]int{3}, []int64{7, 8, 9})
+	mixed, _ := Where(mask, a, ints)
+	if mixed.DType() != Float64 || !reflect.DeepEqual(mixed.Float64Data(), []float64{7, 2, 9}) {
+		t.Errorf("Where: expected promoted Float64 [7 2 9], got %v %v", mixed.DType(), mixed.data)
+	}
+
+	if _, err := Where(a, a, b); err == nil {
+		t.Error("Where: expected error for non-Bool condition")
+	}
+	if _, err := Where(mask, mask, a); err == nil {
+		t.Error("Where: expected error when mixing Bool and numeric")
+	}
+	wide, _ := NewNdArray([]int{2}, []float32{1, 2})
+	if _, err := Where(mask, wide, b); err == nil {
+		t.Error("Where: expected error for incompatible shapes")
+	}
+}
+
+func TestComparisonInto(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+	b, _ := NewNdArray([]int{2, 2}, []float64{2, 2, 2, 2})
+	out := make([]bool, 4)
+
+	cases := []struct {
+		name     string
+		into     func([]bool, *NdArray, *NdArray) error
+		expected []bool
+	}{
+		{"EqInto", EqInto, []bool{false, true, false, false}},
+		{"NeqInto", NeqInto, []bool{true, false, true, true}},
+		{"LtInto", LtInto, []bool{true, false, false, false}},
+		{"LteInto", LteInto, []bool{true, true, false, false}},
+		{"GtInto", GtInto, []bool{false, false, true, true}},
+		{"GteInto", GteInto, []bool{false, true, true, true}},
+	}
+	for _, c := range cases {
+		if err := c.into(out, a, b); err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.name, err)
+		}
+		if !reflect.DeepEqual(out, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, out)
+		}
+	}
+
+	if err := GtInto(make([]bool, 3), a, b); err == nil {
+		t.Error("GtInto: expected error for wrong output length")
+	}
+	p, _ := NewNdArray([]int{2}, []bool{true, false})
+	q, _ := NewNdArray([]int{2}, []bool{true, true})
+	if err := LtInto(make([]bool, 2), p, q); err == nil {
+		t.Error("LtInto: expected error for Bool operands")
+	}
+	eq := make([]bool, 2)
+	if err := EqInto(eq, p, q); err != nil || !reflect.DeepEqual(eq, []bool{true, false}) {
+		t.Errorf("EqInto bool: expected [true false], got %v (err %v)", eq, err)
+	}
+}
+
 func TestBooleanOperations(t *testing.T) {
 	boolData := []bool{true, false, true, false}
 	a, _ := NewNdArray([]int{4}, boolData)
@@ -357,6 +850,62 @@ func TestBooleanOperations(t *testing.T) {
 	if !reflect.DeepEqual(gtRes.BoolData(), expectedGt) {
 		t.Errorf("Gt: expected %v, got %v", expectedGt, gtRes.BoolData())
 	}
+
+	// Comparisons broadcast: [2,1] against [3] gives [2,3].
+	col, _ := NewNdArray([]int{2, 1}, []float32{1, 2})
+	row, _ := NewNdArray([]int{3}, []float32{0, 1, 2})
+	lteRes, err := Lte(col, row)
+	if err != nil {
+		t.Fatalf("Lte broadcast: unexpected error: %v", err)
+	}
+	expectedLte := []bool{false, true, true, false, false, true}
+	if !reflect.DeepEqual(lteRes.Shape(), []int{2, 3}) || !reflect.DeepEqual(lteRes.BoolData(), expectedLte) {
+		t.Errorf("Lte broadcast: expected [2 3] %v, got %v %v", expectedLte, lteRes.Shape(), lteRes.BoolData())
+	}
+	flags, _ := NewNdArray([]int{2, 1}, []bool{true, false})
+	if res, err := Neq(flags, TrueArray([]int{2})); err != nil || !reflect.DeepEqual(res.BoolData(), []bool{false, false, true, true}) {
+		t.Errorf("Neq broadcast: expected [false false true true], got %v (err %v)", res, err)
+	}
+	if _, err := Eq(f1, row); err == nil {
+		t.Error("Eq: expected error for incompatible shapes")
+	}
+	if err := LtInto(make([]bool, 6), col, row); err == nil {
+		t.Error("LtInto: expected error for unequal shapes")
+	}
+}
+
+func TestScalarComparisons(t *testing.T) {
+	a, _ := NewNdArray([]int{4}, []float64{-1, 0, 1, 2})
+
+	tests := []struct {
+		name     string
+		got      *NdArray
+		expected []bool
+	}{
+		{"GtScalar", a.GtScalar(0), []bool{false, false, true, true}},
+		{"GeScalar", a.GeScalar(0), []bool{false, true, true, true}},
+		{"LtScalar", a.LtScalar(0), []bool{true, false, false, false}},
+		{"LeScalar", a.LeScalar(0), []bool{true, true, false, false}},
+		{"EqScalar", a.EqScalar(1), []bool{false, false, true, false}},
+		{"NeScalar", a.NeScalar(1), []bool{true, true, false, true}},
+	}
+	for _, tt := range tests {
+		if tt.got.DType() != Bool {
+			t.Errorf("%s: expected Bool dtype, got %v", tt.name, tt.got.DType())
+		}
+		if !reflect.DeepEqual(tt.got.BoolData(), tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, tt.got.BoolData())
+		}
+	}
+
+	b, _ := NewNdArray([]int{2, 2}, []float32{-1, 0.5, 2, -3})
+	mask := b.GtScalar(0)
+	if !reflect.DeepEqual(mask.BoolData(), []bool{false, true, true, false}) {
+		t.Errorf("GtScalar float32: expected [false true true false], got %v", mask.BoolData())
+	}
+	if !reflect.DeepEqual(mask.Shape(), []int{2, 2}) {
+		t.Errorf("GtScalar float32: expected shape [2 2], got %v", mask.Shape())
+	}
 }
 
 func TestReshape(t *testing.T) {
@@ -374,6 +923,61 @@ func TestReshape(t *testing.T) {
 	if err == nil {
 		t.Error("Reshape: expected error for incompatible shape, got nil")
 	}
+
+	// The receiver keeps its shape while the data buffer is shared
+	if !reflect.DeepEqual(a.Shape(), []int{2, 3}) {
+		t.Errorf("Reshape: receiver shape changed to %v", a.Shape())
+	}
+	reshaped.Float64Data()[0] = 99
+	if a.Float64Data()[0] != 99 {
+		t.Error("Reshape: expected the result to share the data buffer")
+	}
+
+	inferred, err := a.Reshape([]int{-1, 2, 1})
+	if err != nil {
+		t.Fatalf("Reshape: unexpected error inferring a dimension: %v", err)
+	}
+	if !reflect.DeepEqual(inferred.Shape(), []int{3, 2, 1}) {
+		t.Errorf("Reshape: expected inferred shape [3 2 1], got %v", inferred.Shape())
+	}
+	if _, err := a.Reshape([]int{-1, 4}); err == nil {
+		t.Error("Reshape: expected error when -1 cannot be inferred")
+	}
+	if _, err := a.Reshape([]int{-1, -1}); err == nil {
+		t.Error("Reshape: expected error for more than one -1")
+	}
+	if _, err := a.Reshape([]int{-2, -3}); err == nil {
+		t.Error("Reshape: expected error for negative dimensions")
+	}
+}
+
+func TestResize(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+
+	grown := a.Resize([]int{2, 3})
+	if !reflect.DeepEqual(grown.Shape(), []int{2, 3}) {
+		t.Errorf("Resize: expected shape [2 3], got %v", grown.Shape())
+	}
+	expected := []float64{1, 2, 3, 4, 0, 0}
+	if !reflect.DeepEqual(grown.Float64Data(), expected) {
+		t.Errorf("Resize: expected %v, got %v", expected, grown.Float64Data())
+	}
+
+	shrunk := a.Resize([]int{3})
+	if !reflect.DeepEqual(shrunk.Float64Data(), []float64{1, 2, 3}) {
+		t.Errorf("Resize: expected [1 2 3], got %v", shrunk.Float64Data())
+	}
+
+	// Resize copies, so mutations don't leak back
+	shrunk.Float64Data()[0] = 99
+	if a.Float64Data()[0] == 99 {
+		t.Error("Resize: result should not share data with the original")
+	}
+
+	b, _ := NewNdArray([]int{2}, []float32{5, 6})
+	if r := b.Resize([]int{3}); r.DType() != Float32 || !reflect.DeepEqual(r.Float32Data(), []float32{5, 6, 0}) {
+		t.Errorf("Resize float32: expected Float32 [5 6 0], got %v", r)
+	}
 }
 
 func TestInsertAxis(t *testing.T) {
@@ -396,49 +1000,401 @@ func TestInsertAxis(t *testing.T) {
 	}
 }
 
-func TestCopy(t *testing.T) {
-	a, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
-	b := a.Copy()
-
-	// Modify original
-	a.Float64Data()[0] = 99
+func TestShapeAliasing(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 3, 4, 5, 6})
 
-	if b.Float64Data()[0] == 99 {
-		t.Error("Copy: modifying original should not affect the copy")
+	// Mutating a returned shape must not corrupt the array
+	s := a.Shape()
+	s[0] = 99
+	if _, err := a.Get([]int{1, 2}); err != nil {
+		t.Errorf("Shape: mutation of returned slice leaked into array: %v", err)
 	}
-}
 
-func TestString(t *testing.T) {
-	a, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
-	s := a.String()
-	if s == "" {
-		t.Error("String: expected non-empty string")
+	// InsertAxis results must not share shape state with their source
+	expanded, _ := a.InsertAxis(1)
+	es := expanded.Shape()
+	es[0] = 99
+	if !reflect.DeepEqual(expanded.Shape(), []int{2, 1, 3}) || !reflect.DeepEqual(a.Shape(), []int{2, 3}) {
+		t.Errorf("InsertAxis: shape aliasing detected, got %v and %v", expanded.Shape(), a.Shape())
 	}
-}
 
-func TestLinspace(t *testing.T) {
-	// Normal case
-	result := Linspace(0, 1, 5)
-	if len(result) != 5 {
-		t.Fatalf("Linspace: expected 5 points, got %d", len(result))
+	// Mutating the source's shape after InsertAxis must not corrupt the view,
+	// while element writes are shared by design
+	src, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 3, 4, 5, 6})
+	view, _ := src.InsertAxis(0)
+	src.shape[0] = 6
+	src.shape[1] = 1
+	if !reflect.DeepEqual(view.Shape(), []int{1, 2, 3}) {
+		t.Errorf("InsertAxis: expected shape [1 2 3] after mutating source shape, got %v", view.Shape())
 	}
-	if result[0] != 0 || result[4] != 1 {
-		t.Errorf("Linspace: expected [0, ..., 1], got %v", result)
+	src.Float64Data()[5] = 60
+	if v, _ := view.Get([]int{0, 1, 2}); v != 60 {
+		t.Errorf("InsertAxis: expected view to share data, got %v", v)
 	}
 
-	// Single point case (was a div-by-zero bug)
-	single := Linspace(5, 10, 1)
-	if len(single) != 1 || single[0] != 5 {
-		t.Errorf("Linspace(5,10,1): expected [5], got %v", single)
+	// Reshape must copy the caller's shape slice
+	target := []int{3, 2}
+	b, _ := NewNdArray([]int{6}, []float64{1, 2, 3, 4, 5, 6})
+	reshaped, _ := b.Reshape(target)
+	target[0] = 99
+	if !reflect.DeepEqual(reshaped.Shape(), []int{3, 2}) {
+		t.Errorf("Reshape: expected shape [3 2] after mutating argument, got %v", reshaped.Shape())
 	}
 
-	// Zero points
+	if _, err := a.InsertAxis(3); err == nil {
+		t.Error("InsertAxis: expected error for out-of-range position")
+	}
+	if _, err := a.InsertAxis(-4); err == nil {
+		t.Error("InsertAxis: expected error for out-of-range negative position")
+	}
+}
+
+func TestFlattenRavel(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float32{1, 2, 3, 4})
+
+	flat := a.Flatten()
+	if !reflect.DeepEqual(flat.Shape(), []int{4}) || !reflect.DeepEqual(flat.Float32Data(), []float32{1, 2, 3, 4}) {
+		t.Errorf("Flatten: expected [4] [1 2 3 4], got %v %v", flat.Shape(), flat.Float32Data())
+	}
+	raveled := a.Ravel()
+	if !reflect.DeepEqual(raveled.Shape(), []int{4}) || raveled.DType() != Float32 {
+		t.Errorf("Ravel: expected Float32 [4], got %v %v", raveled.DType(), raveled.Shape())
+	}
+
+	a.Float32Data()[3] = 40
+	if flat.Float32Data()[3] != 4 {
+		t.Error("Flatten: result should not share the input buffer")
+	}
+	if raveled.Float32Data()[3] != 40 {
+		t.Error("Ravel: result should share the input buffer")
+	}
+}
+
+func TestSqueeze(t *testing.T) {
+	a, _ := NewNdArray([]int{1, 3, 1, 4}, make([]float64, 12))
+
+	all, err := a.Squeeze()
+	if err != nil {
+		t.Fatalf("Squeeze: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(all.Shape(), []int{3, 4}) {
+		t.Errorf("Squeeze: expected [3 4], got %v", all.Shape())
+	}
+
+	some, err := a.Squeeze(-2)
+	if err != nil {
+		t.Fatalf("Squeeze: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(some.Shape(), []int{1, 3, 4}) {
+		t.Errorf("Squeeze: expected [1 3 4], got %v", some.Shape())
+	}
+
+	a.Float64Data()[0] = 5
+	if all.Float64Data()[0] != 5 {
+		t.Error("Squeeze: expected result to share the data buffer")
+	}
+
+	if _, err := a.Squeeze(1); err == nil {
+		t.Error("Squeeze: expected error for axis of size 3")
+	}
+	if _, err := a.Squeeze(4); err == nil {
+		t.Error("Squeeze: expected error for out-of-range axis")
+	}
+}
+
+func TestPad(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+	res, err := a.Pad([][2]int{{1, 0}, {0, 2}}, -1)
+	if err != nil {
+		t.Fatalf("Pad: unexpected error: %v", err)
+	}
+	expected := []float64{
+		-1, -1, -1, -1,
+		1, 2, -1, -1,
+		3, 4, -1, -1,
+	}
+	if !reflect.DeepEqual(res.Shape(), []int{3, 4}) || !reflect.DeepEqual(res.Float64Data(), expected) {
+		t.Errorf("Pad: expected [3 4] %v, got %v %v", expected, res.Shape(), res.Float64Data())
+	}
+
+	seq, _ := NewNdArray([]int{2}, []int64{5, 6})
+	padded, _ := seq.Pad([][2]int{{2, 1}}, 0)
+	if !reflect.DeepEqual(padded.Int64Data(), []int64{0, 0, 5, 6, 0}) {
+		t.Errorf("Pad: expected [0 0 5 6 0], got %v", padded.Int64Data())
+	}
+
+	if _, err := a.Pad([][2]int{{1, 1}}, 0); err == nil {
+		t.Error("Pad: expected error for widths/rank mismatch")
+	}
+	if _, err := a.Pad([][2]int{{0, 0}, {-1, 0}}, 0); err == nil {
+		t.Error("Pad: expected error for negative width")
+	}
+}
+
+func TestSet(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, make([]float64, 6))
+	if err := a.Set([]int{1, 2}, 7.5); err != nil {
+		t.Fatalf("Set: unexpected error: %v", err)
+	}
+	if v, _ := a.Get([]int{1, 2}); v != 7.5 {
+		t.Errorf("Set: expected 7.5 at [1 2], got %v", v)
+	}
+	if a.Float64Data()[5] != 7.5 {
+		t.Errorf("Set: expected flat offset 5 to hold 7.5, got %v", a.Float64Data())
+	}
+
+	f32, _ := NewNdArray([]int{2}, []float32{0, 0})
+	f32.Set([]int{1}, 0.1)
+	if f32.Float32Data()[1] != float32(0.1) {
+		t.Errorf("Set: expected float32(0.1), got %v", f32.Float32Data()[1])
+	}
+
+	if err := a.Set([]int{2, 0}, 1); err == nil {
+		t.Error("Set: expected error for out-of-bounds index")
+	}
+	if err := a.Set([]int{1}, 1); err == nil {
+		t.Error("Set: expected error for wrong index length")
+	}
+	b, _ := NewNdArray([]int{1}, []bool{false})
+	if err := b.Set([]int{0}, 1); err == nil {
+		t.Error("Set: expected error for Bool array")
+	}
+}
+
+func TestSlice(t *testing.T) {
+	data := make([]float64, 16)
+	for i := range data {
+		data[i] = float64(i)
+	}
+	a, _ := NewNdArray([]int{4, 4}, data)
+
+	block, err := a.Slice([2]int{1, 3}, [2]int{0, 2})
+	if err != nil {
+		t.Fatalf("Slice: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(block.Shape(), []int{2, 2}) || !reflect.DeepEqual(block.Float64Data(), []float64{4, 5, 8, 9}) {
+		t.Errorf("Slice: expected [2 2] [4 5 8 9], got %v %v", block.Shape(), block.Float64Data())
+	}
+	block.Float64Data()[0] = -1
+	if a.Float64Data()[4] != 4 {
+		t.Error("Slice: result should not share the input buffer")
+	}
+
+	rows, _ := a.Slice([2]int{3, 4})
+	if !reflect.DeepEqual(rows.Float64Data(), []float64{12, 13, 14, 15}) {
+		t.Errorf("Slice: expected last row, got %v", rows.Float64Data())
+	}
+	empty, _ := a.Slice([2]int{2, 2})
+	if !reflect.DeepEqual(empty.Shape(), []int{0, 4}) {
+		t.Errorf("Slice: expected shape [0 4], got %v", empty.Shape())
+	}
+
+	b, _ := NewNdArray([]int{2, 2, 2}, []bool{true, false, false, true, true, true, false, false})
+	sub, _ := b.Slice([2]int{1, 2}, [2]int{0, 2}, [2]int{1, 2})
+	if !reflect.DeepEqual(sub.BoolData(), []bool{true, false}) {
+		t.Errorf("Slice: expected [true false], got %v", sub.BoolData())
+	}
+
+	if _, err := a.Slice([2]int{0, 5}); err == nil {
+		t.Error("Slice: expected error for stop past the end")
+	}
+	if _, err := a.Slice([2]int{2, 1}); err == nil {
+		t.Error("Slice: expected error for start after stop")
+	}
+	if _, err := a.Slice([2]int{0, 1}, [2]int{0, 1}, [2]int{0, 1}); err == nil {
+		t.Error("Slice: expected error for too many ranges")
+	}
+}
+
+func TestCopy(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+	b := a.Copy()
+
+	// Modify original
+	a.Float64Data()[0] = 99
+
+	if b.Float64Data()[0] == 99 {
+		t.Error("Copy: modifying original should not affect the copy")
+	}
+
+	a.shape[0] = 4
+	if !reflect.DeepEqual(b.Shape(), []int{2, 2}) {
+		t.Errorf("Copy: expected independent shape [2 2], got %v", b.Shape())
+	}
+
+	inputs := []any{
+		[]float32{1, 2},
+		[]bool{true, false},
+		[]int64{1, 2},
+	}
+	for _, data := range inputs {
+		src, _ := NewNdArray([]int{2}, data)
+		dst := src.Copy()
+		if dst.DType() != src.DType() || !reflect.DeepEqual(dst.data, src.data) {
+			t.Errorf("Copy: expected %v %v, got %v %v", src.DType(), src.data, dst.DType(), dst.data)
+		}
+	}
+	f32, _ := NewNdArray([]int{2}, []float32{1, 2})
+	f32Copy := f32.Copy()
+	f32.Float32Data()[0] = 99
+	if f32Copy.Float32Data()[0] != 1 {
+		t.Error("Copy: Float32 copy should not share the data buffer")
+	}
+}
+
+func TestCopyTo(t *testing.T) {
+	src, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+	dst, _ := NewNdArray([]int{2, 3}, make([]float32, 6))
+
+	if err := src.CopyTo(dst); err != nil {
+		t.Fatalf("CopyTo: unexpected error: %v", err)
+	}
+	expected := []float32{1, 2, 3, 1, 2, 3}
+	if !reflect.DeepEqual(dst.Float32Data(), expected) {
+		t.Errorf("CopyTo: expected %v, got %v", expected, dst.Float32Data())
+	}
+
+	small, _ := NewNdArray([]int{2}, []float64{0, 0})
+	if err := src.CopyTo(small); err == nil {
+		t.Error("CopyTo: expected error when source cannot broadcast into destination")
+	}
+	big := Zeros([]int{2, 3})
+	other, _ := NewNdArray([]int{2, 1}, []float64{7, 8})
+	if err := other.CopyTo(big); err != nil {
+		t.Fatalf("CopyTo: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(big.Float64Data(), []float64{7, 7, 7, 8, 8, 8}) {
+		t.Errorf("CopyTo: expected [7 7 7 8 8 8], got %v", big.Float64Data())
+	}
+
+	mask, _ := NewNdArray([]int{3}, []bool{true, false, true})
+	if err := mask.CopyTo(big); err == nil {
+		t.Error("CopyTo: expected error copying Bool into numeric array")
+	}
+}
+
+func TestString(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []float64{1, 2.5, 3, 4, 5, 66})
+	want := "NdArray(shape=[2 3], dtype=float64)\n" +
+		"[[  1, 2.5,   3],\n" +
+		" [  4,   5,  66]]"
+	if got := a.String(); got != want {
+		t.Errorf("String: expected %q, got %q", want, got)
+	}
+
+	b, _ := NewNdArray([]int{2, 1, 2}, []bool{true, false, false, true})
+	want = "NdArray(shape=[2 1 2], dtype=bool)\n" +
+		"[[[ true, false]],\n" +
+		"\n" +
+		" [[false,  true]]]"
+	if got := b.String(); got != want {
+		t.Errorf("String rank 3: expected %q, got %q", want, got)
+	}
+
+	big := Zeros([]int{2000})
+	want = "NdArray(shape=[2000], dtype=float64)\n[0, 0, 0, ..., 0, 0, 0]"
+	if got := big.String(); got != want {
+		t.Errorf("String large: expected %q, got %q", want, got)
+	}
+	grid := Zeros([]int{100, 100})
+	if lines := strings.Count(grid.String(), "\n") + 1; lines != 8 {
+		t.Errorf("String large 2-D: expected a header and 3+1+3 rows, got %d lines", lines)
+	}
+}
+
+func TestFormatString(t *testing.T) {
+	a, _ := NewNdArray([]int{4}, []float64{1, 2.5, 1.0 / 3.0, 4})
+
+	if got, want := a.String(), "NdArray(shape=[4], dtype=float64)\n[1, 2.5, 0.3333333333333333, 4]"; got != want {
+		t.Errorf("String: expected %q, got %q", want, got)
+	}
+	if got, want := a.FormatString(2, 0), "NdArray(shape=[4], dtype=float64)\n[1.00, 2.50, 0.33, 4.00]"; got != want {
+		t.Errorf("FormatString(2, 0): expected %q, got %q", want, got)
+	}
+	if got, want := a.FormatString(-1, 2), "NdArray(shape=[4], dtype=float64)\n[1, ..., 4]"; got != want {
+		t.Errorf("FormatString(-1, 2): expected %q, got %q", want, got)
+	}
+
+	b, _ := NewNdArray([]int{2}, []float32{0.1, 0.2})
+	if got, want := b.FormatString(-1, 10), "NdArray(shape=[2], dtype=float32)\n[0.1, 0.2]"; got != want {
+		t.Errorf("FormatString float32: expected %q, got %q", want, got)
+	}
+}
+
+func TestFormatVerbs(t *testing.T) {
+	a, _ := NewNdArray([]int{2}, []float64{1.23456, 1000})
+
+	if got, want := fmt.Sprintf("%.3g", a), "NdArray(shape=[2], dtype=float64)\n[1.23, 1e+03]"; got != want {
+		t.Errorf("%%.3g: expected %q, got %q", want, got)
+	}
+	if got, want := fmt.Sprintf("%8.2f", a), "NdArray(shape=[2], dtype=float64)\n[    1.23,  1000.00]"; got != want {
+		t.Errorf("%%8.2f: expected %q, got %q", want, got)
+	}
+	if got, want := fmt.Sprintf("%.1e", a), "NdArray(shape=[2], dtype=float64)\n[1.2e+00, 1.0e+03]"; got != want {
+		t.Errorf("%%.1e: expected %q, got %q", want, got)
+	}
+	if got := fmt.Sprintf("%v", a); got != a.String() {
+		t.Errorf("%%v: expected %q, got %q", a.String(), got)
+	}
+	if got := fmt.Sprintf("%d", a); got != "%!d(*ndvek.NdArray)" {
+		t.Errorf("%%d: expected bad verb marker, got %q", got)
+	}
+
+	b, _ := NewNdArray([]int{2}, []bool{true, false})
+	if got, want := fmt.Sprintf("%.2f", b), "NdArray(shape=[2], dtype=bool)\n[true, false]"; got != want {
+		t.Errorf("%%.2f bool: expected %q, got %q", want, got)
+	}
+}
+
+func TestLinspace(t *testing.T) {
+	// Normal case
+	result := Linspace(0, 1, 5)
+	if len(result) != 5 {
+		t.Fatalf("Linspace: expected 5 points, got %d", len(result))
+	}
+	if result[0] != 0 || result[4] != 1 {
+		t.Errorf("Linspace: expected [0, ..., 1], got %v", result)
+	}
+
+	// Single point case (was a div-by-zero bug)
+	single := Linspace(5, 10, 1)
+	if len(single) != 1 || single[0] != 5 {
+		t.Errorf("Linspace(5,10,1): expected [5], got %v", single)
+	}
+
+	// Zero points
 	empty := Linspace(0, 1, 0)
 	if empty != nil {
 		t.Errorf("Linspace(0,1,0): expected nil, got %v", empty)
 	}
 }
 
+func TestLinspaceEx(t *testing.T) {
+	result := LinspaceEx(0, 1, 4, false)
+	expected := []float64{0, 0.25, 0.5, 0.75}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("LinspaceEx: expected %v, got %v", expected, result)
+	}
+
+	result = LinspaceEx(0, 1, 5, true)
+	if !reflect.DeepEqual(result, Linspace(0, 1, 5)) {
+		t.Errorf("LinspaceEx: expected endpoint variant to match Linspace, got %v", result)
+	}
+
+	single := LinspaceEx(5, 10, 1, false)
+	if !reflect.DeepEqual(single, []float64{5}) {
+		t.Errorf("LinspaceEx(5,10,1,false): expected [5], got %v", single)
+	}
+
+	values, step := LinspaceStep(0, 2, 4, false)
+	if step != 0.5 || len(values) != 4 {
+		t.Errorf("LinspaceStep: expected step 0.5 with 4 values, got %v %v", step, values)
+	}
+	if _, step := LinspaceStep(0, 2, 1, true); !math.IsNaN(step) {
+		t.Errorf("LinspaceStep: expected NaN step for a single point, got %v", step)
+	}
+}
+
 func TestZerosAndOnes(t *testing.T) {
 	z := Zeros([]int{3, 2})
 	if z.DType() != Float64 {
@@ -455,6 +1411,68 @@ func TestZerosAndOnes(t *testing.T) {
 	if o.Sum() != 6 {
 		t.Errorf("Ones: expected sum 6, got %v", o.Sum())
 	}
+
+	f := Full([]int{2, 2}, 0.5)
+	if !reflect.DeepEqual(f.Shape(), []int{2, 2}) || !reflect.DeepEqual(f.Float64Data(), []float64{0.5, 0.5, 0.5, 0.5}) {
+		t.Errorf("Full: expected [2 2] of 0.5, got %v %v", f.Shape(), f.Float64Data())
+	}
+}
+
+func TestZerosDType(t *testing.T) {
+	z := ZerosDType([]int{2, 3}, Float32)
+	if z.DType() != Float32 || !reflect.DeepEqual(z.Float32Data(), make([]float32, 6)) {
+		t.Errorf("ZerosDType: expected Float32 zeros, got %v %v", z.DType(), z.data)
+	}
+
+	src, _ := NewNdArray([]int{2}, []int64{4, 5})
+	like := ZerosLike(src)
+	if like.DType() != Int64 || !reflect.DeepEqual(like.Shape(), []int{2}) || !reflect.DeepEqual(like.Int64Data(), []int64{0, 0}) {
+		t.Errorf("ZerosLike: expected Int64 [0 0], got %v %v", like.DType(), like.data)
+	}
+	like.shape[0] = 9
+	if src.shape[0] != 2 {
+		t.Error("ZerosLike: result should not share the source shape")
+	}
+
+	if b := ZerosDType([]int{1}, Bool); !reflect.DeepEqual(b.BoolData(), []bool{false}) {
+		t.Errorf("ZerosDType: expected [false], got %v", b.BoolData())
+	}
+}
+
+func TestFill(t *testing.T) {
+	a, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+	buf := a.Float64Data()
+	a.Fill(-2)
+	if !reflect.DeepEqual(a.Float64Data(), []float64{-2, -2, -2}) || &buf[0] != &a.Float64Data()[0] {
+		t.Errorf("Fill: expected [-2 -2 -2] in the same buffer, got %v", a.Float64Data())
+	}
+
+	b, _ := NewNdArray([]int{2}, []float32{0, 0})
+	b.Fill(0.25)
+	if !reflect.DeepEqual(b.Float32Data(), []float32{0.25, 0.25}) {
+		t.Errorf("Fill: expected float32 [0.25 0.25], got %v", b.Float32Data())
+	}
+
+	c := FalseArray([]int{2})
+	c.Fill(1)
+	if !reflect.DeepEqual(c.BoolData(), []bool{true, true}) {
+		t.Errorf("Fill: expected [true true], got %v", c.BoolData())
+	}
+}
+
+func TestBoolConstructors(t *testing.T) {
+	f := FalseArray([]int{2, 2})
+	if f.DType() != Bool || !reflect.DeepEqual(f.Shape(), []int{2, 2}) {
+		t.Errorf("FalseArray: expected Bool [2 2], got %v", f)
+	}
+	if none, _ := f.None(); !none {
+		t.Error("FalseArray: expected all elements false")
+	}
+
+	tr := TrueArray([]int{3})
+	if all, _ := tr.All(); !all || tr.DType() != Bool {
+		t.Errorf("TrueArray: expected all-true Bool array, got %v", tr)
+	}
 }
 
 func TestNewVekOperations(t *testing.T) {
@@ -492,9 +1510,9 @@ func TestNewVekOperations(t *testing.T) {
 
 	// Median
 	c, _ := NewNdArray([]int{5}, []float64{3, 1, 4, 1, 5})
-	med := c.Median()
+	med := c.MedianAll()
 	if med != 3 {
-		t.Errorf("Median: expected 3, got %v", med)
+		t.Errorf("MedianAll: expected 3, got %v", med)
 	}
 
 	// ArgMin / ArgMax
@@ -514,6 +1532,21 @@ func TestNewVekOperations(t *testing.T) {
 	if dotRes != 19 {
 		t.Errorf("Dot: expected 19, got %v", dotRes)
 	}
+	a32, _ := NewNdArray([]int{3}, []float32{1, 2, 3})
+	if dot32, err := Dot(a32, a32); err != nil || dot32 != 14 {
+		t.Errorf("Dot float32: expected 14, got %v (err %v)", dot32, err)
+	}
+	if _, err := Dot(a, a32); err == nil {
+		t.Error("Dot: expected error for mismatched lengths")
+	}
+	m, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+	if _, err := Dot(m, a); err == nil {
+		t.Error("Dot: expected error for a 2-D operand")
+	}
+	mask, _ := NewNdArray([]int{4}, []bool{true, false, true, false})
+	if _, err := Dot(a, mask); err == nil {
+		t.Error("Dot: expected error for Bool operand")
+	}
 
 	// Norm
 	d, _ := NewNdArray([]int{2}, []float64{3, 4})
@@ -544,6 +1577,346 @@ func TestNewVekOperations(t *testing.T) {
 	}
 }
 
+func TestThresholds(t *testing.T) {
+	a, _ := NewNdArray([]int{5}, []float64{-3, -0.5, 0, 0.5, 3})
+
+	hard := a.HardThreshold(1)
+	expected := []float64{-3, 0, 0, 0, 3}
+	if !reflect.DeepEqual(hard.Float64Data(), expected) {
+		t.Errorf("HardThreshold: expected %v, got %v", expected, hard.Float64Data())
+	}
+
+	soft := a.SoftThreshold(1)
+	expected = []float64{-2, 0, 0, 0, 2}
+	if !reflect.DeepEqual(soft.Float64Data(), expected) {
+		t.Errorf("SoftThreshold: expected %v, got %v", expected, soft.Float64Data())
+	}
+
+	// The originals must be untouched by the non-in-place variants
+	if a.Float64Data()[1] != -0.5 {
+		t.Error("SoftThreshold: should not modify the receiver")
+	}
+
+	b, _ := NewNdArray([]int{3}, []float32{-2, 0.25, 4})
+	b.SoftThresholdInPlace(0.5)
+	if !reflect.DeepEqual(b.Float32Data(), []float32{-1.5, 0, 3.5}) {
+		t.Errorf("SoftThresholdInPlace float32: expected [-1.5 0 3.5], got %v", b.Float32Data())
+	}
+	b.HardThresholdInPlace(2)
+	if !reflect.DeepEqual(b.Float32Data(), []float32{0, 0, 3.5}) {
+		t.Errorf("HardThresholdInPlace float32: expected [0 0 3.5], got %v", b.Float32Data())
+	}
+
+	c, _ := NewNdArray([]int{3}, []int64{-3, 1, 5})
+	if res := c.HardThreshold(2); res.DType() != Float64 || !reflect.DeepEqual(res.Float64Data(), []float64{-3, 0, 5}) {
+		t.Errorf("HardThreshold int64: expected Float64 [-3 0 5], got %v", res)
+	}
+	if res := c.SoftThreshold(2); res.DType() != Float64 || !reflect.DeepEqual(res.Float64Data(), []float64{-1, 0, 3}) {
+		t.Errorf("SoftThreshold int64: expected Float64 [-1 0 3], got %v", res)
+	}
+	if !reflect.DeepEqual(c.Int64Data(), []int64{-3, 1, 5}) {
+		t.Error("SoftThreshold int64: should not modify the receiver")
+	}
+
+	d, _ := NewNdArray([]int{3}, []float64{math.NaN(), 0.5, 2})
+	if got := d.SoftThreshold(1).Float64Data(); !math.IsNaN(got[0]) || got[1] != 0 || got[2] != 1 {
+		t.Errorf("SoftThreshold: expected [NaN 0 1], got %v", got)
+	}
+	if got := d.HardThreshold(1).Float64Data(); !math.IsNaN(got[0]) || got[1] != 0 || got[2] != 2 {
+		t.Errorf("HardThreshold: expected [NaN 0 2], got %v", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("SoftThreshold: expected panic for negative threshold")
+		}
+	}()
+	d.SoftThreshold(-1)
+}
+
+func TestClip(t *testing.T) {
+	nan := math.NaN()
+	a, _ := NewNdArray([]int{5}, []float64{-5, -0.5, 0.5, 5, nan})
+
+	clipped := a.Clip(-1, 1)
+	got := clipped.Float64Data()
+	if !reflect.DeepEqual(got[:4], []float64{-1, -0.5, 0.5, 1}) || !math.IsNaN(got[4]) {
+		t.Errorf("Clip: expected [-1 -0.5 0.5 1 NaN], got %v", got)
+	}
+	if a.Float64Data()[0] != -5 {
+		t.Error("Clip: should not modify the receiver")
+	}
+
+	lower := a.Clip(0, math.Inf(1)).Float64Data()
+	if !reflect.DeepEqual(lower[:4], []float64{0, 0, 0.5, 5}) {
+		t.Errorf("Clip: expected [0 0 0.5 5] with no upper bound, got %v", lower)
+	}
+
+	b, _ := NewNdArray([]int{3}, []float32{-2, 0.25, 4})
+	b.ClipInPlace(math.Inf(-1), 1)
+	if !reflect.DeepEqual(b.Float32Data(), []float32{-2, 0.25, 1}) {
+		t.Errorf("ClipInPlace float32: expected [-2 0.25 1], got %v", b.Float32Data())
+	}
+
+	c, _ := NewNdArray([]int{3}, []int64{-5, 0, 5})
+	if res := c.Clip(-1, 2); res.DType() != Float64 || !reflect.DeepEqual(res.Float64Data(), []float64{-1, 0, 2}) {
+		t.Errorf("Clip int64: expected Float64 [-1 0 2], got %v", res)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "Clip not supported for Bool") {
+				t.Errorf("Clip: expected explicit panic for Bool input, got %v", r)
+			}
+		}()
+		TrueArray([]int{2}).Clip(0, 1)
+	}()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Clip: expected panic when lo > hi")
+		}
+	}()
+	a.Clip(1, -1)
+}
+
+func TestSafeLog(t *testing.T) {
+	a, _ := NewNdArray([]int{4}, []float64{0, -1, 1, math.E})
+	res := a.SafeLog(1e-10)
+	expected := []float64{math.Log(1e-10), math.Log(1e-10), 0, 1}
+	if !reflect.DeepEqual(res.Float64Data(), expected) {
+		t.Errorf("SafeLog: expected %v, got %v", expected, res.Float64Data())
+	}
+	if a.Float64Data()[0] != 0 {
+		t.Error("SafeLog: input should be unchanged")
+	}
+
+	b, _ := NewNdArray([]int{2}, []float32{0, 1})
+	b.SafeLogInPlace(1e-3)
+	if got := b.Float32Data(); got[0] != float32(math.Log(1e-3)) || got[1] != 0 {
+		t.Errorf("SafeLogInPlace: expected [%v 0], got %v", float32(math.Log(1e-3)), got)
+	}
+
+	c, _ := NewNdArray([]int{2}, []int64{0, 1})
+	if res := c.SafeLog(1e-10); res.DType() != Float64 || !reflect.DeepEqual(res.Float64Data(), []float64{math.Log(1e-10), 0}) {
+		t.Errorf("SafeLog int64: expected Float64 [%v 0], got %v", math.Log(1e-10), res)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "Bool") {
+				t.Errorf("SafeLog: expected explicit panic for Bool input, got %v", r)
+			}
+		}()
+		TrueArray([]int{2}).SafeLog(1e-10)
+	}()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("SafeLog: expected panic for non-positive eps")
+		}
+	}()
+	a.SafeLog(0)
+}
+
+func TestSnapTo(t *testing.T) {
+	a, _ := NewNdArray([]int{5}, []float64{-1.3, 0.2, 0.25, 0.74, 2})
+
+	snapped := a.SnapTo(0.5)
+	expected := []float64{-1.5, 0, 0.5, 0.5, 2}
+	if !reflect.DeepEqual(snapped.Float64Data(), expected) {
+		t.Errorf("SnapTo: expected %v, got %v", expected, snapped.Float64Data())
+	}
+	if a.Float64Data()[0] != -1.3 {
+		t.Error("SnapTo: should not modify the receiver")
+	}
+
+	b, _ := NewNdArray([]int{3}, []float32{7, 13, -4})
+	b.SnapToInPlace(5)
+	if !reflect.DeepEqual(b.Float32Data(), []float32{5, 15, -5}) {
+		t.Errorf("SnapToInPlace float32: expected [5 15 -5], got %v", b.Float32Data())
+	}
+
+	c, _ := NewNdArray([]int{3}, []int64{7, 13, -4})
+	if res := c.SnapTo(5); res.DType() != Float64 || !reflect.DeepEqual(res.Float64Data(), []float64{5, 15, -5}) {
+		t.Errorf("SnapTo int64: expected Float64 [5 15 -5], got %v", res)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("SnapTo: expected panic for non-positive step")
+		}
+	}()
+	a.SnapTo(0)
+}
+
+func TestReplace(t *testing.T) {
+	nan := math.NaN()
+	a, _ := NewNdArray([]int{4}, []float64{-999, 1, -999, 2})
+
+	res := a.Replace(-999, 0)
+	if !reflect.DeepEqual(res.Float64Data(), []float64{0, 1, 0, 2}) {
+		t.Errorf("Replace: expected [0 1 0 2], got %v", res.Float64Data())
+	}
+	if a.Float64Data()[0] != -999 {
+		t.Error("Replace: should not modify the receiver")
+	}
+
+	res = a.Replace(-999, nan)
+	if !math.IsNaN(res.Float64Data()[0]) || !math.IsNaN(res.Float64Data()[2]) {
+		t.Errorf("Replace: expected NaN substitutions, got %v", res.Float64Data())
+	}
+	res = res.Replace(nan, -1)
+	if !reflect.DeepEqual(res.Float64Data(), []float64{-1, 1, -1, 2}) {
+		t.Errorf("Replace NaN: expected [-1 1 -1 2], got %v", res.Float64Data())
+	}
+
+	b, _ := NewNdArray([]int{3}, []float32{0.1, 0.1001, 0.2})
+	res = b.ReplaceClose(0.1, 0, 1e-3)
+	if res.DType() != Float32 || !reflect.DeepEqual(res.Float32Data(), []float32{0, 0, 0.2}) {
+		t.Errorf("ReplaceClose: expected Float32 [0 0 0.2], got %v", res)
+	}
+
+	b.ReplaceInPlace(0.2, 5)
+	if b.Float32Data()[2] != 5 {
+		t.Errorf("ReplaceInPlace: expected last element 5, got %v", b.Float32Data())
+	}
+
+	inf, _ := NewNdArray([]int{3}, []float64{math.Inf(1), 1, math.Inf(-1)})
+	if res := inf.Replace(math.Inf(1), 0); !reflect.DeepEqual(res.Float64Data(), []float64{0, 1, math.Inf(-1)}) {
+		t.Errorf("Replace +Inf: expected [0 1 -Inf], got %v", res.Float64Data())
+	}
+	if res := inf.ReplaceClose(math.Inf(-1), 0, 0.1); !reflect.DeepEqual(res.Float64Data(), []float64{math.Inf(1), 1, 0}) {
+		t.Errorf("ReplaceClose -Inf: expected [+Inf 1 0], got %v", res.Float64Data())
+	}
+
+	c, _ := NewNdArray([]int{3}, []int64{-1, 4, -1})
+	if res := c.Replace(-1, 0); res.DType() != Float64 || !reflect.DeepEqual(res.Float64Data(), []float64{0, 4, 0}) {
+		t.Errorf("Replace int64: expected Float64 [0 4 0], got %v", res)
+	}
+	if res := c.ReplaceClose(4, 9, 0.5); !reflect.DeepEqual(res.Float64Data(), []float64{-1, 9, -1}) {
+		t.Errorf("ReplaceClose int64: expected [-1 9 -1], got %v", res)
+	}
+}
+
+func TestMaskBySign(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []float64{1, 2, 3, 4, 5, 6})
+	ref, _ := NewNdArray([]int{3}, []float64{-1, 0, 2})
+
+	tests := []struct {
+		keep     string
+		expected []float64
+	}{
+		{"positive", []float64{0, 0, 3, 0, 0, 6}},
+		{"negative", []float64{1, 0, 0, 4, 0, 0}},
+		{"nonneg", []float64{0, 2, 3, 0, 5, 6}},
+	}
+	for _, tt := range tests {
+		res, err := a.MaskBySign(ref, tt.keep)
+		if err != nil {
+			t.Fatalf("MaskBySign(%q): unexpected error: %v", tt.keep, err)
+		}
+		if !reflect.DeepEqual(res.Float64Data(), tt.expected) {
+			t.Errorf("MaskBySign(%q): expected %v, got %v", tt.keep, tt.expected, res.Float64Data())
+		}
+	}
+	if a.Float64Data()[0] != 1 {
+		t.Error("MaskBySign: should not modify the receiver")
+	}
+
+	if _, err := a.MaskBySign(ref, "zero"); err == nil {
+		t.Error("MaskBySign: expected error for invalid keep")
+	}
+	bad, _ := NewNdArray([]int{2}, []float64{1, 1})
+	if _, err := a.MaskBySign(bad, "positive"); err == nil {
+		t.Error("MaskBySign: expected error for incompatible ref shape")
+	}
+}
+
+func TestPowBroadcast(t *testing.T) {
+	base, _ := NewNdArray([]int{2, 1}, []float32{-2, 3})
+	exp, _ := NewNdArray([]int{3}, []float32{2, 3, 0.5})
+
+	res, err := Pow(base, exp)
+	if err != nil {
+		t.Fatalf("Pow: unexpected error: %v", err)
+	}
+	if res.DType() != Float32 || !reflect.DeepEqual(res.Shape(), []int{2, 3}) {
+		t.Fatalf("Pow: expected Float32 [2 3], got %v %v", res.DType(), res.Shape())
+	}
+	got := res.Float32Data()
+	if got[0] != 4 || got[1] != -8 || !math.IsNaN(float64(got[2])) {
+		t.Errorf("Pow: expected [4 -8 NaN] for base -2, got %v", got[:3])
+	}
+	if got[3] != 9 || got[4] != 27 {
+		t.Errorf("Pow: expected [9 27 ...] for base 3, got %v", got[3:])
+	}
+
+	mixed, _ := Pow(base, NewScalar(2))
+	if mixed.DType() != Float64 {
+		t.Errorf("Pow: expected Float64 for mixed dtypes, got %v", mixed.DType())
+	}
+}
+
+func TestPowScalar(t *testing.T) {
+	a, _ := NewNdArray([]int{4}, []float64{1, 2, -3, 0.5})
+
+	tests := []struct {
+		p        float64
+		expected []float64
+	}{
+		{0, []float64{1, 1, 1, 1}},
+		{2, []float64{1, 4, 9, 0.25}},
+		{3, []float64{1, 8, -27, 0.125}},
+		{4, []float64{1, 16, 81, 0.0625}},
+		{-2, []float64{1, 0.25, 1.0 / 9.0, 4}},
+		{5, []float64{1, 32, -243, 0.03125}},
+	}
+	for _, tt := range tests {
+		res := a.PowScalar(tt.p)
+		got := res.Float64Data()
+		for i := range got {
+			if math.Abs(got[i]-tt.expected[i]) > 1e-12 {
+				t.Errorf("PowScalar(%v): expected %v, got %v", tt.p, tt.expected, got)
+				break
+			}
+		}
+	}
+
+	// Fractional powers of negatives fall back to math.Pow and yield NaN
+	if v := a.PowScalar(0.5).Float64Data()[2]; !math.IsNaN(v) {
+		t.Errorf("PowScalar(0.5): expected NaN for negative base, got %v", v)
+	}
+
+	b, _ := NewNdArray([]int{2}, []float32{2, 3})
+	res := b.PowScalar(2)
+	if res.DType() != Float32 || !reflect.DeepEqual(res.Float32Data(), []float32{4, 9}) {
+		t.Errorf("PowScalar float32: expected Float32 [4 9], got %v", res)
+	}
+}
+
+func TestRPowScalar(t *testing.T) {
+	a, _ := NewNdArray([]int{4}, []float64{-1, 0, 1, 3})
+
+	res := RPowScalar(2, a)
+	if !reflect.DeepEqual(res.Float64Data(), []float64{0.5, 1, 2, 8}) {
+		t.Errorf("RPowScalar(2): expected [0.5 1 2 8], got %v", res.Float64Data())
+	}
+	res = RPowScalar(10, a)
+	if !reflect.DeepEqual(res.Float64Data(), []float64{0.1, 1, 10, 1000}) {
+		t.Errorf("RPowScalar(10): expected [0.1 1 10 1000], got %v", res.Float64Data())
+	}
+	res = RPowScalar(math.E, a)
+	if !reflect.DeepEqual(res.Float64Data(), a.Exp().Float64Data()) {
+		t.Errorf("RPowScalar(e): expected %v, got %v", a.Exp().Float64Data(), res.Float64Data())
+	}
+
+	b, _ := NewNdArray([]int{2}, []float32{1, 2})
+	if res := RPowScalar(3, b); res.DType() != Float32 || !reflect.DeepEqual(res.Float32Data(), []float32{3, 9}) {
+		t.Errorf("RPowScalar float32: expected Float32 [3 9], got %v", res)
+	}
+}
+
 func TestTranscendentals(t *testing.T) {
 	a, _ := NewNdArray([]int{3}, []float64{0, math.Pi / 2, math.Pi})
 	const eps = 1e-10
@@ -589,6 +1962,167 @@ func TestTranscendentals(t *testing.T) {
 	}
 }
 
+func TestInverseTrig(t *testing.T) {
+	a, _ := NewNdArray([]int{3}, []float64{0, 0.5, 1})
+	const eps = 1e-12
+
+	tan := a.Tan().Float64Data()
+	if tan[0] != 0 || math.Abs(tan[2]-math.Tan(1)) > eps {
+		t.Errorf("Tan: unexpected result %v", tan)
+	}
+	asin := a.Asin().Float64Data()
+	if math.Abs(asin[1]-math.Pi/6) > eps || math.Abs(asin[2]-math.Pi/2) > eps {
+		t.Errorf("Asin: unexpected result %v", asin)
+	}
+	acos := a.Acos().Float64Data()
+	if math.Abs(acos[0]-math.Pi/2) > eps || acos[2] != 0 {
+		t.Errorf("Acos: unexpected result %v", acos)
+	}
+	atan := a.Atan().Float64Data()
+	if math.Abs(atan[2]-math.Pi/4) > eps {
+		t.Errorf("Atan: unexpected result %v", atan)
+	}
+
+	b, _ := NewNdArray([]int{2}, []float32{2, -1})
+	res := b.Asin()
+	if res.DType() != Float32 || !math.IsNaN(float64(res.Float32Data()[0])) {
+		t.Errorf("Asin float32: expected Float32 with NaN for 2, got %v", res)
+	}
+	if got := res.Float32Data()[1]; got != float32(-math.Pi/2) {
+		t.Errorf("Asin float32: expected %v, got %v", float32(-math.Pi/2), got)
+	}
+}
+
+func TestSinCos(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float64{0, math.Pi / 2, math.Pi, 1})
+	sin, cos := a.SinCos()
+	if !reflect.DeepEqual(sin.Float64Data(), a.Sin().Float64Data()) || !reflect.DeepEqual(cos.Float64Data(), a.Cos().Float64Data()) {
+		t.Errorf("SinCos: expected to match Sin and Cos, got %v and %v", sin, cos)
+	}
+	if !reflect.DeepEqual(sin.Shape(), []int{2, 2}) {
+		t.Errorf("SinCos: expected shape [2 2], got %v", sin.Shape())
+	}
+
+	b, _ := NewNdArray([]int{3}, []float32{0, math.Pi / 2, math.Pi})
+	sin, cos = b.SinCos()
+	if sin.DType() != Float32 || cos.DType() != Float32 {
+		t.Fatalf("SinCos: expected Float32 results, got %v and %v", sin.DType(), cos.DType())
+	}
+	s, c := sin.Float32Data(), cos.Float32Data()
+	if math.Abs(float64(s[1])-1) > 1e-6 || math.Abs(float64(c[2])+1) > 1e-6 {
+		t.Errorf("SinCos float32: unexpected result %v and %v", s, c)
+	}
+}
+
+func TestExpLogSpecialValues(t *testing.T) {
+	inf, nan := math.Inf(1), math.NaN()
+	// Long enough that the float32 kernels take their SIMD path
+	vals := []float64{0, -1, inf, nan, 1e-45, 1, 0, -1, inf, nan, 1e-45, 1, 0, -1, inf, nan, 1e-45}
+
+	f64, _ := NewNdArray([]int{len(vals)}, vals)
+	f32vals := make([]float32, len(vals))
+	for i, v := range vals {
+		f32vals[i] = float32(v)
+	}
+	f32, _ := NewNdArray([]int{len(vals)}, f32vals)
+
+	same := func(got, want float64) bool {
+		return got == want || (math.IsNaN(got) && math.IsNaN(want)) || math.Abs(got-want) <= 1e-5*math.Abs(want)
+	}
+	for _, tt := range []struct {
+		name string
+		op   func(*NdArray) *NdArray
+		ref  func(float64) float64
+	}{
+		{"Log", (*NdArray).Log, math.Log},
+		{"Log2", (*NdArray).Log2, math.Log2},
+		{"Log10", (*NdArray).Log10, math.Log10},
+	} {
+		d64, d32 := tt.op(f64).Float64Data(), tt.op(f32).Float32Data()
+		for i, v := range vals {
+			want := tt.ref(v)
+			if !same(d64[i], want) {
+				t.Errorf("%s(%v): expected %v, got %v", tt.name, v, want, d64[i])
+			}
+			if want32 := tt.ref(float64(f32vals[i])); !same(float64(d32[i]), want32) {
+				t.Errorf("%s float32(%v): expected %v, got %v", tt.name, f32vals[i], want32, d32[i])
+			}
+		}
+	}
+
+	x, _ := NewNdArray([]int{17}, []float32{0, 1, 100, -100, float32(inf), float32(-inf), float32(nan),
+		0, 1, 100, -100, float32(inf), float32(-inf), float32(nan), 0, 1, 2})
+	got := x.Exp().Float32Data()
+	if got[0] != 1 || !math.IsInf(float64(got[2]), 1) || got[3] != float32(math.Exp(-100)) ||
+		!math.IsInf(float64(got[4]), 1) || got[5] != 0 || !math.IsNaN(float64(got[6])) {
+		t.Errorf("Exp float32: expected [1 e +Inf exp(-100) +Inf 0 NaN ...], got %v", got[:7])
+	}
+}
+
+func TestAssertAllClose(t *testing.T) {
+	nan := math.NaN()
+	a, _ := NewNdArray([]int{2, 2}, []float64{1, 2, nan, 4})
+	b, _ := NewNdArray([]int{2, 2}, []float64{1, 2.0000001, nan, 4})
+
+	if err := a.AssertAllClose(b, 1e-6, 0); err != nil {
+		t.Errorf("AssertAllClose: unexpected error: %v", err)
+	}
+	if ok, err := AllClose(a, b, 0, 0); err != nil || ok {
+		t.Errorf("AllClose: expected false with zero tolerance, got %v (err %v)", ok, err)
+	}
+
+	c, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+	err := a.AssertAllClose(c, 1e-6, 1e-6)
+	if err == nil || !strings.Contains(err.Error(), "[1 0]") {
+		t.Errorf("AssertAllClose: expected error naming coordinate [1 0], got %v", err)
+	}
+
+	// Broadcasting a row against the matrix
+	row, _ := NewNdArray([]int{2}, []float32{1, 2})
+	m, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 1, 2.5})
+	err = m.AssertAllClose(row, 0, 0.1)
+	if err == nil || !strings.Contains(err.Error(), "[1 1]") {
+		t.Errorf("AssertAllClose: expected broadcast mismatch at [1 1], got %v", err)
+	}
+	if ok, _ := AllClose(m, row, 0, 1); !ok {
+		t.Error("AllClose: expected true within atol")
+	}
+
+	bad, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+	if _, err := AllClose(a, bad, 0, 0); err == nil {
+		t.Error("AllClose: expected error for incompatible shapes")
+	}
+}
+
+func TestMaskedSelectAssign(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 3}, []int64{1, 2, 3, 4, 5, 6})
+	mask, _ := NewNdArray([]int{2, 3}, []bool{true, false, true, false, false, true})
+
+	sel, err := a.MaskedSelect(mask)
+	if err != nil {
+		t.Fatalf("MaskedSelect: unexpected error: %v", err)
+	}
+	if sel.DType() != Int64 || !reflect.DeepEqual(sel.Shape(), []int{3}) || !reflect.DeepEqual(sel.Int64Data(), []int64{1, 3, 6}) {
+		t.Errorf("MaskedSelect: expected Int64 [1 3 6], got %v %v", sel.DType(), sel.data)
+	}
+
+	f, _ := NewNdArray([]int{2, 3}, []float32{1, 2, 3, 4, 5, 6})
+	if err := f.MaskedAssign(mask, -0.5); err != nil {
+		t.Fatalf("MaskedAssign: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(f.Float32Data(), []float32{-0.5, 2, -0.5, 4, 5, -0.5}) {
+		t.Errorf("MaskedAssign: expected [-0.5 2 -0.5 4 5 -0.5], got %v", f.Float32Data())
+	}
+
+	flat, _ := NewNdArray([]int{6}, []bool{true, true, true, true, true, true})
+	if _, err := a.MaskedSelect(flat); err == nil {
+		t.Error("MaskedSelect: expected error for mask shape mismatch")
+	}
+	if err := f.MaskedAssign(f, 0); err == nil {
+		t.Error("MaskedAssign: expected error for non-Bool mask")
+	}
+}
+
 func TestSelect(t *testing.T) {
 	mask, _ := NewNdArray([]int{4}, []bool{true, false, true, false})
 	a, _ := NewNdArray([]int{4}, []float64{1, 2, 3, 4})
@@ -606,6 +2140,75 @@ func TestSelect(t *testing.T) {
 	}
 }
 
+func TestPadStack(t *testing.T) {
+	a, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+	b, _ := NewNdArray([]int{1}, []float64{4})
+	c, _ := NewNdArray([]int{0}, []float64{})
+
+	res, mask, err := PadStackMask([]*NdArray{a, b, c}, -1)
+	if err != nil {
+		t.Fatalf("PadStackMask: unexpected error: %v", err)
+	}
+	expected := []float64{1, 2, 3, 4, -1, -1, -1, -1, -1}
+	if !reflect.DeepEqual(res.Shape(), []int{3, 3}) || !reflect.DeepEqual(res.Float64Data(), expected) {
+		t.Errorf("PadStack: expected [3 3] %v, got %v", expected, res)
+	}
+	expectedMask := []bool{true, true, true, true, false, false, false, false, false}
+	if !reflect.DeepEqual(mask.BoolData(), expectedMask) {
+		t.Errorf("PadStackMask: expected mask %v, got %v", expectedMask, mask.BoolData())
+	}
+
+	d, _ := NewNdArray([]int{2}, []float32{1, 2})
+	e, _ := NewNdArray([]int{1}, []float32{3})
+	res, _ = PadStack([]*NdArray{d, e}, 0)
+	if res.DType() != Float32 || !reflect.DeepEqual(res.Float32Data(), []float32{1, 2, 3, 0}) {
+		t.Errorf("PadStack float32: expected Float32 [1 2 3 0], got %v", res)
+	}
+
+	if _, err := PadStack(nil, 0); err == nil {
+		t.Error("PadStack: expected error for no arrays")
+	}
+	m, _ := NewNdArray([]int{1, 2}, []float64{1, 2})
+	if _, err := PadStack([]*NdArray{a, m}, 0); err == nil {
+		t.Error("PadStack: expected error for a 2-D array")
+	}
+}
+
+func TestIndexRows(t *testing.T) {
+	a, _ := NewNdArray([]int{3, 2}, []float64{1, 2, 3, 4, 5, 6})
+	idx, _ := NewNdArray([]int{4}, []float64{2, 0, -1, 0})
+
+	res, err := a.IndexRows(idx)
+	if err != nil {
+		t.Fatalf("IndexRows: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(res.Shape(), []int{4, 2}) {
+		t.Errorf("IndexRows: expected shape [4 2], got %v", res.Shape())
+	}
+	expected := []float64{5, 6, 1, 2, 5, 6, 1, 2}
+	if !reflect.DeepEqual(res.Float64Data(), expected) {
+		t.Errorf("IndexRows: expected %v, got %v", expected, res.Float64Data())
+	}
+
+	emb, _ := NewNdArray([]int{2, 2}, []float32{0.5, 1, 1.5, 2})
+	idx32, _ := NewNdArray([]int{1}, []float32{1})
+	if res, _ := emb.IndexRows(idx32); res.DType() != Float32 || !reflect.DeepEqual(res.Float32Data(), []float32{1.5, 2}) {
+		t.Errorf("IndexRows float32: expected Float32 [1.5 2], got %v", res)
+	}
+
+	frac, _ := NewNdArray([]int{1}, []float64{0.5})
+	if _, err := a.IndexRows(frac); err == nil {
+		t.Error("IndexRows: expected error for non-integer index")
+	}
+	oob, _ := NewNdArray([]int{1}, []float64{-4})
+	if _, err := a.IndexRows(oob); err == nil {
+		t.Error("IndexRows: expected error for out-of-range index")
+	}
+	if _, err := idx.IndexRows(idx); err == nil {
+		t.Error("IndexRows: expected error for 1-D source")
+	}
+}
+
 func TestInPlaceOperations(t *testing.T) {
 	a, _ := NewNdArray([]int{4}, []float64{1, 2, 3, 4})
 	b, _ := NewNdArray([]int{4}, []float64{5, 6, 7, 8})
@@ -620,6 +2223,205 @@ func TestInPlaceOperations(t *testing.T) {
 	}
 }
 
+func TestInPlaceInt64(t *testing.T) {
+	a, _ := NewNdArray([]int{3}, []int64{1 << 60, -2, 3})
+	b, _ := NewNdArray([]int{3}, []int64{1, 5, -3})
+
+	if err := a.AddInPlace(b); err != nil || !reflect.DeepEqual(a.Int64Data(), []int64{1<<60 + 1, 3, 0}) {
+		t.Errorf("AddInPlace int64: expected [%d 3 0], got %v (err %v)", int64(1<<60+1), a.Int64Data(), err)
+	}
+	if err := a.SubtractInPlace(b); err != nil || !reflect.DeepEqual(a.Int64Data(), []int64{1 << 60, -2, 3}) {
+		t.Errorf("SubtractInPlace int64: expected [%d -2 3], got %v (err %v)", int64(1<<60), a.Int64Data(), err)
+	}
+	if err := a.MultiplyInPlace(b); err != nil || !reflect.DeepEqual(a.Int64Data(), []int64{1 << 60, -10, -9}) {
+		t.Errorf("MultiplyInPlace int64: expected [%d -10 -9], got %v (err %v)", int64(1<<60), a.Int64Data(), err)
+	}
+	if err := a.DivideInPlace(b); err == nil {
+		t.Error("DivideInPlace: expected error for Int64 receiver")
+	}
+	a.AbsInPlace()
+	if !reflect.DeepEqual(a.Int64Data(), []int64{1 << 60, 10, 9}) {
+		t.Errorf("AbsInPlace int64: expected [%d 10 9], got %v", int64(1<<60), a.Int64Data())
+	}
+	a.NegInPlace()
+	if !reflect.DeepEqual(a.Int64Data(), []int64{-1 << 60, -10, -9}) {
+		t.Errorf("NegInPlace int64: expected [%d -10 -9], got %v", int64(-1<<60), a.Int64Data())
+	}
+
+	f64, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+	f32, _ := NewNdArray([]int{3}, []float32{1, 2, 3})
+	mask := TrueArray([]int{3})
+	if err := a.AddInPlace(f64); err == nil {
+		t.Error("AddInPlace: expected error for Int64 receiver with Float64 operand")
+	}
+	if err := f32.AddInPlace(b); err == nil {
+		t.Error("AddInPlace: expected error for Float32 receiver with Int64 operand")
+	}
+	if err := f64.AddInPlace(mask); err == nil {
+		t.Error("AddInPlace: expected error for Bool operand")
+	}
+	if err := mask.MultiplyInPlace(mask); err == nil {
+		t.Error("MultiplyInPlace: expected error for Bool receiver")
+	}
+
+	for name, fn := range map[string]func(){
+		"ClipNormInPlace":  func() { a.ClipNormInPlace(1) },
+		"ClipInPlace":      func() { a.ClipInPlace(0, 1) },
+		"AddScalarInPlace": func() { a.AddScalarInPlace(1) },
+		"SqrtInPlace":      func() { a.SqrtInPlace() },
+		"AbsInPlace":       func() { mask.AbsInPlace() },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "floating-point") {
+					t.Errorf("%s: expected a floating-point dtype panic, got %v", name, r)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestNormalizeSumInPlace(t *testing.T) {
+	counts, _ := NewNdArray([]int{3, 2}, []float64{1, 3, 0, 0, 2, 2})
+	if err := counts.NormalizeSumInPlace(1); err != nil {
+		t.Fatalf("NormalizeSumInPlace: unexpected error: %v", err)
+	}
+	expected := []float64{0.25, 0.75, 0, 0, 0.5, 0.5}
+	if !reflect.DeepEqual(counts.Float64Data(), expected) {
+		t.Errorf("NormalizeSumInPlace: expected %v, got %v", expected, counts.Float64Data())
+	}
+
+	cols, _ := NewNdArray([]int{2, 2}, []float32{1, 1, 3, 1})
+	if err := cols.NormalizeSumInPlace(0); err != nil {
+		t.Fatalf("NormalizeSumInPlace: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cols.Float32Data(), []float32{0.25, 0.5, 0.75, 0.5}) {
+		t.Errorf("NormalizeSumInPlace: expected [0.25 0.5 0.75 0.5], got %v", cols.Float32Data())
+	}
+
+	strict, _ := NewNdArray([]int{2, 2}, []float64{1, 1, 0, 0})
+	if err := strict.NormalizeSumStrictInPlace(-1); err == nil {
+		t.Error("NormalizeSumStrictInPlace: expected error for zero-sum slice")
+	}
+	if !reflect.DeepEqual(strict.Float64Data(), []float64{1, 1, 0, 0}) {
+		t.Errorf("NormalizeSumStrictInPlace: expected input untouched on error, got %v", strict.Float64Data())
+	}
+	if err := strict.NormalizeSumInPlace(2); err == nil {
+		t.Error("NormalizeSumInPlace: expected error for out-of-range axis")
+	}
+}
+
+func TestClipNormInPlace(t *testing.T) {
+	a, _ := NewNdArray([]int{2}, []float64{3, 4})
+
+	if norm := a.ClipNormInPlace(10); norm != 5 {
+		t.Errorf("ClipNormInPlace: expected norm 5, got %v", norm)
+	}
+	if !reflect.DeepEqual(a.Float64Data(), []float64{3, 4}) {
+		t.Errorf("ClipNormInPlace: expected unchanged [3 4], got %v", a.Float64Data())
+	}
+
+	if norm := a.ClipNormInPlace(1); norm != 5 {
+		t.Errorf("ClipNormInPlace: expected pre-clip norm 5, got %v", norm)
+	}
+	if math.Abs(a.Norm()-1) > 1e-12 {
+		t.Errorf("ClipNormInPlace: expected norm 1 after clipping, got %v", a.Norm())
+	}
+
+	b, _ := NewNdArray([]int{2}, []float32{6, 8})
+	b.ClipNormInPlace(5)
+	if !reflect.DeepEqual(b.Float32Data(), []float32{3, 4}) {
+		t.Errorf("ClipNormInPlace float32: expected [3 4], got %v", b.Float32Data())
+	}
+
+	c, _ := NewNdArray([]int{3}, []float64{math.Inf(-1), 3, 4})
+	if norm := c.ClipNormInPlace(1); !math.IsInf(norm, 1) {
+		t.Errorf("ClipNormInPlace: expected +Inf norm, got %v", norm)
+	}
+	if got := c.Float64Data(); !math.IsInf(got[0], -1) || got[1] != 3 || got[2] != 4 {
+		t.Errorf("ClipNormInPlace: expected [-Inf 3 4] left unchanged, got %v", got)
+	}
+	d, _ := NewNdArray([]int{2}, []float64{math.NaN(), 4})
+	if norm := d.ClipNormInPlace(1); !math.IsNaN(norm) || d.Float64Data()[1] != 4 {
+		t.Errorf("ClipNormInPlace: expected NaN norm and unchanged data, got %v %v", norm, d.Float64Data())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("ClipNormInPlace: expected panic for negative maxNorm")
+		}
+		if !reflect.DeepEqual(b.Float32Data(), []float32{3, 4}) {
+			t.Errorf("ClipNormInPlace: expected [3 4] left unchanged, got %v", b.Float32Data())
+		}
+	}()
+	b.ClipNormInPlace(-1)
+}
+
+func TestApplyOpInPlace(t *testing.T) {
+	a, _ := NewNdArray([]int{2, 2}, []float64{1, 2, 3, 4})
+	b, _ := NewNdArray([]int{2}, []float64{10, 20})
+
+	err := a.ApplyOpInPlace(b, func(x, y float64) float64 { return x*y + 1 })
+	if err != nil {
+		t.Fatalf("ApplyOpInPlace: unexpected error: %v", err)
+	}
+	expected := []float64{11, 41, 31, 81}
+	if !reflect.DeepEqual(a.Float64Data(), expected) {
+		t.Errorf("ApplyOpInPlace: expected %v, got %v", expected, a.Float64Data())
+	}
+
+	c, _ := NewNdArray([]int{2}, []float32{1, 2})
+	big, _ := NewNdArray([]int{2, 2}, []float64{1, 1, 1, 1})
+	if err := c.ApplyOpInPlace(big, math.Max); err == nil {
+		t.Error("ApplyOpInPlace: expected error when result would need to grow")
+	}
+	if err := c.ApplyOpInPlace(NewScalar(1.5), math.Max); err == nil {
+		t.Error("ApplyOpInPlace: expected error for Float32 receiver with Float64 operand")
+	}
+	if err := c.ApplyOpInPlace(NewScalar32(1.5), math.Max); err != nil {
+		t.Fatalf("ApplyOpInPlace float32: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(c.Float32Data(), []float32{1.5, 2}) {
+		t.Errorf("ApplyOpInPlace float32: expected [1.5 2], got %v", c.Float32Data())
+	}
+}
+
+func TestEMAInPlace(t *testing.T) {
+	a, _ := NewNdArray([]int{3}, []float64{1, 2, 3})
+	b, _ := NewNdArray([]int{3}, []float64{3, 4, 5})
+
+	if err := a.EMAInPlace(b, 0.75); err != nil {
+		t.Fatalf("EMAInPlace: unexpected error: %v", err)
+	}
+	expected := []float64{1.5, 2.5, 3.5}
+	if !reflect.DeepEqual(a.Float64Data(), expected) {
+		t.Errorf("EMAInPlace: expected %v, got %v", expected, a.Float64Data())
+	}
+
+	if err := a.EMAInPlace(b, 1.5); err == nil {
+		t.Error("EMAInPlace: expected error for decay outside [0, 1]")
+	}
+
+	c, _ := NewNdArray([]int{2}, []float32{2, 4})
+	d, _ := NewNdArray([]int{2}, []float32{4, 8})
+	if err := c.EMAInPlace(d, 0.5); err != nil {
+		t.Fatalf("EMAInPlace float32: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(c.Float32Data(), []float32{3, 6}) {
+		t.Errorf("EMAInPlace float32: expected [3 6], got %v", c.Float32Data())
+	}
+
+	i64, _ := NewNdArray([]int{2}, []int64{4, 8})
+	if err := c.EMAInPlace(i64, 0.5); err == nil {
+		t.Error("EMAInPlace: expected error for Float32 receiver with Int64 operand")
+	}
+	e, _ := NewNdArray([]int{2}, []float64{2, 4})
+	if err := e.EMAInPlace(i64, 0.5); err != nil || !reflect.DeepEqual(e.Float64Data(), []float64{3, 6}) {
+		t.Errorf("EMAInPlace int64 operand: expected [3 6], got %v (err %v)", e.Float64Data(), err)
+	}
+}
+
 func TestApplyOpErrorHandling(t *testing.T) {
 	// Bool arrays should return error through ApplyOp, not panic
 	boolArr, _ := NewNdArray([]int{2}, []bool{true, false})
@@ -0,0 +1,193 @@
+package ndvek
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BroadcastView is a read-only view of an array broadcast to a larger shape.
+// Broadcast axes have a zero stride, so elements are read through to the
+// source array's backing slice without materializing a copy.
+type BroadcastView struct {
+	src     *NdArray
+	shape   []int
+	strides []int
+}
+
+// Broadcast returns a lazy view of a broadcast to shape. The view shares a's
+// data and keeps its dtype, so later writes to a are visible through it.
+func (a *NdArray) Broadcast(shape []int) (*BroadcastView, error) {
+	bShape, err := broadcastShapes(a.shape, shape)
+	if err != nil || !shapesEqual(bShape, shape) {
+		return nil, fmt.Errorf("cannot broadcast shape %v to %v", a.shape, shape)
+	}
+	if a.dtype == Bool {
+		return nil, errors.New("Broadcast not supported for Bool arrays")
+	}
+	shapeCopy := make([]int, len(shape))
+	copy(shapeCopy, shape)
+	return &BroadcastView{src: a, shape: shapeCopy, strides: broadcastStrides(a.shape, shape)}, nil
+}
+
+// Shape returns a copy of the view's broadcast shape.
+func (v *BroadcastView) Shape() []int {
+	shape := make([]int, len(v.shape))
+	copy(shape, v.shape)
+	return shape
+}
+
+// Size returns the number of elements in the broadcast shape.
+func (v *BroadcastView) Size() int {
+	return ProdInt(v.shape)
+}
+
+// DType returns the dtype of the source array.
+func (v *BroadcastView) DType() DType {
+	return v.src.dtype
+}
+
+// At returns the element at the given multi-index of the broadcast shape.
+func (v *BroadcastView) At(index []int) (float64, error) {
+	if len(index) != len(v.shape) {
+		return 0, errors.New("index length does not match array dimensions")
+	}
+	offset := 0
+	for i, coord := range index {
+		if coord < 0 || coord >= v.shape[i] {
+			return 0, errors.New("index out of bounds")
+		}
+		offset += coord * v.strides[i]
+	}
+	return v.reader()(offset), nil
+}
+
+// reader returns a function reading the source element at a flat offset as
+// float64 straight from the typed backing slice.
+func (v *BroadcastView) reader() func(int) float64 {
+	switch d := v.src.data.(type) {
+	case []float32:
+		return func(i int) float64 { return float64(d[i]) }
+	case []int64:
+		return func(i int) float64 { return float64(d[i]) }
+	default:
+		d64 := v.src.data.([]float64)
+		return func(i int) float64 { return d64[i] }
+	}
+}
+
+// walkPair calls fn with the source offsets under strides1 and strides2 for
+// every element of shape in row-major order.
+func walkPair(shape, strides1, strides2 []int, fn func(off1, off2 int)) {
+	size := ProdInt(shape)
+	if size == 0 {
+		return
+	}
+	coord := make([]int, len(shape))
+	off1, off2 := 0, 0
+	for range size {
+		fn(off1, off2)
+		for ax := len(shape) - 1; ax >= 0; ax-- {
+			coord[ax]++
+			off1 += strides1[ax]
+			off2 += strides2[ax]
+			if coord[ax] < shape[ax] {
+				break
+			}
+			off1 -= coord[ax] * strides1[ax]
+			off2 -= coord[ax] * strides2[ax]
+			coord[ax] = 0
+		}
+	}
+}
+
+// each calls fn with the source offset of every element of the view in
+// row-major order.
+func (v *BroadcastView) each(fn func(offset int)) {
+	walkPair(v.shape, v.strides, v.strides, func(off, _ int) { fn(off) })
+}
+
+// Sum returns the sum over the broadcast shape without materializing it.
+func (v *BroadcastView) Sum() float64 {
+	read := v.reader()
+	sum := 0.0
+	v.each(func(off int) { sum += read(off) })
+	return sum
+}
+
+// Mean returns the mean over the broadcast shape without materializing it.
+func (v *BroadcastView) Mean() float64 {
+	return v.Sum() / float64(v.Size())
+}
+
+// Materialize copies the view into a new contiguous array with the source dtype.
+func (v *BroadcastView) Materialize() *NdArray {
+	idx := make([]int, 0, v.Size())
+	v.each(func(off int) { idx = append(idx, off) })
+	return v.src.gather(v.Shape(), idx)
+}
+
+// ApplyOp applies op to the view and b element-wise, reading both operands
+// through their strides. b must broadcast to the view's shape, which is the
+// shape of the result. The result is Float32 when both operands are Float32
+// and Float64 otherwise.
+func (v *BroadcastView) ApplyOp(b *NdArray, op func(float64, float64) float64) (*NdArray, error) {
+	w, err := b.Broadcast(v.shape)
+	if err != nil {
+		return nil, err
+	}
+	readV, readW := v.reader(), w.reader()
+	out := make([]float64, 0, v.Size())
+	walkPair(v.shape, v.strides, w.strides, func(off1, off2 int) {
+		out = append(out, op(readV(off1), readW(off2)))
+	})
+	return fromFloat64(v.Shape(), out, promoteDType(v.src, b)), nil
+}
+
+// applyOpInt64 is ApplyOp for an Int64 view and Int64 b, keeping exact
+// integer arithmetic.
+func (v *BroadcastView) applyOpInt64(b *NdArray, op func(int64, int64) int64) (*NdArray, error) {
+	w, err := b.Broadcast(v.shape)
+	if err != nil {
+		return nil, err
+	}
+	vData, wData := v.src.data.([]int64), b.data.([]int64)
+	out := make([]int64, 0, v.Size())
+	walkPair(v.shape, v.strides, w.strides, func(off1, off2 int) {
+		out = append(out, op(vData[off1], wData[off2]))
+	})
+	return &NdArray{shape: v.Shape(), data: out, dtype: Int64}, nil
+}
+
+// Add adds b to the view element-wise without materializing the view. b must
+// broadcast to the view's shape. Dtypes follow Add.
+func (v *BroadcastView) Add(b *NdArray) (*NdArray, error) {
+	if v.src.dtype == Int64 && b.dtype == Int64 {
+		return v.applyOpInt64(b, func(x, y int64) int64 { return x + y })
+	}
+	return v.ApplyOp(b, func(x, y float64) float64 { return x + y })
+}
+
+// Subtract subtracts b from the view element-wise without materializing the
+// view. b must broadcast to the view's shape. Dtypes follow Subtract.
+func (v *BroadcastView) Subtract(b *NdArray) (*NdArray, error) {
+	if v.src.dtype == Int64 && b.dtype == Int64 {
+		return v.applyOpInt64(b, func(x, y int64) int64 { return x - y })
+	}
+	return v.ApplyOp(b, func(x, y float64) float64 { return x - y })
+}
+
+// Multiply multiplies the view by b element-wise without materializing the
+// view. b must broadcast to the view's shape. Dtypes follow Multiply.
+func (v *BroadcastView) Multiply(b *NdArray) (*NdArray, error) {
+	if v.src.dtype == Int64 && b.dtype == Int64 {
+		return v.applyOpInt64(b, func(x, y int64) int64 { return x * y })
+	}
+	return v.ApplyOp(b, func(x, y float64) float64 { return x * y })
+}
+
+// Divide divides the view by b element-wise without materializing the view.
+// b must broadcast to the view's shape. The result is floating point, as for
+// Divide.
+func (v *BroadcastView) Divide(b *NdArray) (*NdArray, error) {
+	return v.ApplyOp(b, func(x, y float64) float64 { return x / y })
+}
@@ -0,0 +1,121 @@
+package ndvek
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBroadcastView(t *testing.T) {
+	a, _ := NewNdArray([]int{3, 1}, []float64{1, 2, 3})
+
+	v, err := a.Broadcast([]int{2, 3, 4})
+	if err != nil {
+		t.Fatalf("Broadcast: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(v.Shape(), []int{2, 3, 4}) {
+		t.Errorf("Broadcast: expected shape [2 3 4], got %v", v.Shape())
+	}
+	if v.Size() != 24 {
+		t.Errorf("Size: expected 24, got %d", v.Size())
+	}
+
+	got, err := v.At([]int{1, 2, 3})
+	if err != nil || got != 3 {
+		t.Errorf("At: expected 3, got %v (err %v)", got, err)
+	}
+	if _, err := v.At([]int{0, 3, 0}); err == nil {
+		t.Error("At: expected error for out-of-bounds index")
+	}
+
+	// Each row value appears 2*4 times
+	if v.Sum() != 48 {
+		t.Errorf("Sum: expected 48, got %v", v.Sum())
+	}
+	if v.Mean() != 2 {
+		t.Errorf("Mean: expected 2, got %v", v.Mean())
+	}
+
+	m := v.Materialize()
+	want, _ := Add(Zeros([]int{2, 3, 4}), a)
+	if !reflect.DeepEqual(m.Float64Data(), want.Float64Data()) {
+		t.Errorf("Materialize: expected %v, got %v", want.Float64Data(), m.Float64Data())
+	}
+
+	b, _ := NewNdArray([]int{2}, []float32{1, 2})
+	vb, _ := b.Broadcast([]int{3, 2})
+	if mb := vb.Materialize(); mb.DType() != Float32 || !reflect.DeepEqual(mb.Float32Data(), []float32{1, 2, 1, 2, 1, 2}) {
+		t.Errorf("Materialize float32: expected Float32 [1 2 1 2 1 2], got %v", mb)
+	}
+
+	if _, err := a.Broadcast([]int{2, 2}); err == nil {
+		t.Error("Broadcast: expected error for incompatible target shape")
+	}
+	if _, err := a.Broadcast([]int{1}); err == nil {
+		t.Error("Broadcast: expected error when target is smaller than source")
+	}
+}
+
+func TestBroadcastViewSharesSource(t *testing.T) {
+	a, _ := NewNdArray([]int{2}, []float32{1, 2})
+	v, _ := a.Broadcast([]int{3, 2})
+	if v.DType() != Float32 {
+		t.Errorf("DType: expected Float32, got %v", v.DType())
+	}
+	a.Float32Data()[1] = 5
+	if got, _ := v.At([]int{2, 1}); got != 5 {
+		t.Errorf("At: expected write to source to be visible, got %v", got)
+	}
+
+	c, _ := NewNdArray([]int{3, 1}, []int64{1, 2, 3})
+	vc, _ := c.Broadcast([]int{3, 2})
+	if m := vc.Materialize(); m.DType() != Int64 || !reflect.DeepEqual(m.Int64Data(), []int64{1, 1, 2, 2, 3, 3}) {
+		t.Errorf("Materialize int64: expected Int64 [1 1 2 2 3 3], got %v", m)
+	}
+}
+
+func TestBroadcastViewBinaryOps(t *testing.T) {
+	a, _ := NewNdArray([]int{3, 1}, []float32{1, 2, 3})
+	b, _ := NewNdArray([]int{3, 4}, []float32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	v, _ := a.Broadcast([]int{3, 4})
+
+	ops := []struct {
+		name string
+		view func(*NdArray) (*NdArray, error)
+		full func(*NdArray, *NdArray) (*NdArray, error)
+	}{
+		{"Add", v.Add, Add},
+		{"Subtract", v.Subtract, Subtract},
+		{"Multiply", v.Multiply, Multiply},
+		{"Divide", v.Divide, Divide},
+	}
+	for _, op := range ops {
+		got, err := op.view(b)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", op.name, err)
+		}
+		want, _ := op.full(a, b)
+		if got.DType() != Float32 || !reflect.DeepEqual(got.Float32Data(), want.Float32Data()) {
+			t.Errorf("%s: expected Float32 %v, got %v", op.name, want.Float32Data(), got)
+		}
+	}
+
+	// The operand broadcasts to the view's shape too
+	row, _ := NewNdArray([]int{4}, []float64{10, 20, 30, 40})
+	got, _ := v.ApplyOp(row, func(x, y float64) float64 { return x + y })
+	want, _ := Add(a, row)
+	if got.DType() != Float64 || !reflect.DeepEqual(got.Float64Data(), want.Float64Data()) {
+		t.Errorf("ApplyOp: expected %v, got %v", want.Float64Data(), got)
+	}
+
+	c, _ := NewNdArray([]int{2}, []int64{1, 2})
+	vc, _ := c.Broadcast([]int{2, 2})
+	d, _ := NewNdArray([]int{2, 1}, []int64{10, 20})
+	if sum, _ := vc.Add(d); sum.DType() != Int64 || !reflect.DeepEqual(sum.Int64Data(), []int64{11, 12, 21, 22}) {
+		t.Errorf("Add int64: expected Int64 [11 12 21 22], got %v", sum)
+	}
+
+	wide := Zeros([]int{2, 3, 4})
+	if _, err := v.Add(wide); err == nil {
+		t.Error("Add: expected error when operand does not broadcast to the view's shape")
+	}
+}
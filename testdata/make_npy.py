@@ -0,0 +1,8 @@
+# Regenerates the .npy fixtures used by TestNpyFixtures:
+#   python3 testdata/make_npy.py
+import numpy as np
+
+np.save("testdata/f8_2x3.npy", np.arange(6, dtype="<f8").reshape(2, 3))
+np.save("testdata/i8_2x3_fortran.npy", np.asfortranarray(np.arange(6, dtype="<i8").reshape(2, 3)))
+np.save("testdata/f4_3.npy", np.array([0.5, -1, np.inf], dtype="<f4"))
+np.save("testdata/b1_2x2.npy", np.eye(2, dtype=bool))